@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toyinlola/shipsafe/pkg/ai/eval"
+	"github.com/toyinlola/shipsafe/pkg/cli"
+	"github.com/toyinlola/shipsafe/pkg/vcs"
+)
+
+var (
+	evalBaseline       string
+	evalUpdateBaseline bool
+	evalLineTolerance  int
+)
+
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "AI review utilities",
+}
+
+var aiEvalCmd = &cobra.Command{
+	Use:   "eval <fixtures-dir>",
+	Short: "Measure AI review quality against labeled fixture diffs",
+	Long: `Eval runs the configured AI review against every labeled diff in a
+directory and reports precision, recall and F1 overall, per pass and per
+category, as JSON. Triage, test suggestions, fix patches and the walkthrough
+are not scored and are turned off for the run.
+
+Each fixture "name.diff" is labeled by "name.expected.json":
+  {"findings": [{"file": "pkg/api.go", "line": 64, "category": "logic", "pass": "logic"}]}
+
+Scores are compared against the baseline file (default:
+<fixtures-dir>/eval-baseline.json, when present) and the command fails if
+any score drops below it. Use the replay provider for deterministic runs:
+  shipsafe ai eval tests/fixtures/diffs --config eval.yml
+  shipsafe ai eval tests/fixtures/diffs --update-baseline`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAIEval,
+}
+
+func init() {
+	aiEvalCmd.Flags().StringVar(&evalBaseline, "baseline", "", "baseline scores file (default: <fixtures-dir>/eval-baseline.json)")
+	aiEvalCmd.Flags().BoolVar(&evalUpdateBaseline, "update-baseline", false, "write the current scores to the baseline file instead of comparing")
+	aiEvalCmd.Flags().IntVar(&evalLineTolerance, "line-tolerance", eval.DefaultLineTolerance, "lines a finding may be from a labeled line and still match")
+	aiCmd.AddCommand(aiEvalCmd)
+	rootCmd.AddCommand(aiCmd)
+}
+
+func runAIEval(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dir := args[0]
+
+	cfg, err := cli.LoadConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("ai eval: %w", err)
+	}
+	// Evaluate the review alone, whatever the scan settings say. The optional
+	// steps are not scored and would only add LLM calls per fixture.
+	cfg.AI.Enabled = true
+	cfg.AI.Triage = false
+	cfg.AI.TestSuggestions = false
+	cfg.AI.FixPatches = false
+	cfg.AI.Walkthrough = false
+
+	cases, err := eval.LoadCases(ctx, dir, vcs.NewDiffParser())
+	if err != nil {
+		return fmt.Errorf("ai eval: %w", err)
+	}
+
+	scorer := eval.NewScorer(evalLineTolerance)
+	for _, c := range cases {
+		slog.Info("evaluating AI review", "fixture", c.Name, "expected", len(c.Expected))
+		result, err := runAIReview(ctx, cfg, c.Diff, nil, ".")
+		if err != nil {
+			return fmt.Errorf("ai eval: %s: %w", c.Name, err)
+		}
+		if result == nil {
+			return fmt.Errorf("ai eval: %s: AI review did not run; check the ai section of the config and the endpoint", c.Name)
+		}
+		scorer.Add(c, result.Findings)
+	}
+	rpt := scorer.Report()
+
+	baselinePath := evalBaseline
+	if baselinePath == "" {
+		baselinePath = filepath.Join(dir, "eval-baseline.json")
+	}
+	switch {
+	case evalUpdateBaseline:
+		if err := eval.SaveBaseline(baselinePath, rpt.Baseline()); err != nil {
+			return fmt.Errorf("ai eval: %w", err)
+		}
+		slog.Info("baseline updated", "path", baselinePath)
+	case fileExists(baselinePath):
+		baseline, err := eval.LoadBaseline(baselinePath)
+		if err != nil {
+			return fmt.Errorf("ai eval: %w", err)
+		}
+		rpt.Compare(*baseline)
+	default:
+		slog.Info("no baseline to compare against", "path", baselinePath)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("ai eval: creating output file: %w", err)
+		}
+		defer file.Close() // best-effort cleanup
+		w = file
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rpt); err != nil {
+		return fmt.Errorf("ai eval: writing report: %w", err)
+	}
+
+	slog.Info("AI review evaluation complete", "fixtures", len(cases),
+		"precision", rpt.Overall.Precision, "recall", rpt.Overall.Recall, "f1", rpt.Overall.F1)
+	if n := len(rpt.Regressions); n > 0 {
+		for _, r := range rpt.Regressions {
+			slog.Error("score below baseline", "group", r.Group, "metric", r.Metric, "baseline", r.Baseline, "actual", r.Actual)
+		}
+		return fmt.Errorf("ai eval: %d score(s) below baseline %s", n, baselinePath)
+	}
+	return nil
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
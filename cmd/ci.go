@@ -35,6 +35,7 @@ Exit code is determined by the fail_on config:
 }
 
 func init() {
+	ciCmd.Flags().BoolVar(&noAICache, "no-ai-cache", false, "bypass the on-disk cache of AI review responses")
 	rootCmd.AddCommand(ciCmd)
 }
 
@@ -208,7 +209,11 @@ func runCI(cmd *cobra.Command, args []string) error {
 	}
 
 	// 6. Run AI review if enabled.
-	if aiResult := runAIReview(ctx, cfg, diff); aiResult != nil {
+	aiResult, err := runAIReview(ctx, cfg, diff, results, ".")
+	if err != nil {
+		return fmt.Errorf("ci: %w", err)
+	}
+	if aiResult != nil {
 		results = append(results, aiResult)
 	}
 
@@ -322,6 +327,27 @@ func postCIComment(ctx context.Context, provider interfaces.VCSProvider, env *ci
 	}
 
 	slog.Info("PR comment posted", "pr", env.PRNumber)
+
+	postCISuggestedChanges(ctx, provider, env, rpt)
+}
+
+// postCISuggestedChanges posts AI fix patches as line-anchored review
+// comments, so authors can apply them in one click, when the provider
+// supports them.
+func postCISuggestedChanges(ctx context.Context, provider interfaces.VCSProvider, env *ciEnvironment, rpt *interfaces.Report) {
+	commenter, ok := provider.(interfaces.ReviewCommenter)
+	if !ok {
+		return
+	}
+	comments := report.SuggestedChanges(rpt)
+	if len(comments) == 0 {
+		return
+	}
+	if err := commenter.PostReviewComments(ctx, env.PRNumber, comments); err != nil {
+		slog.Error("ci: posting suggested changes", "error", err)
+		return
+	}
+	slog.Info("suggested changes posted", "pr", env.PRNumber, "count", len(comments))
 }
 
 // setCIStatus sets the commit status on the head SHA.
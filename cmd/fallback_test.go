@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/cli"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func TestRunAIReview_FallsBackWhenPrimaryIsDown(t *testing.T) {
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"content": "{\"findings\": []}"}}], "usage": {"prompt_tokens": 100, "completion_tokens": 10}}`)) //nolint:errcheck
+	}))
+	defer backup.Close()
+
+	disabled := false
+	cfg := cli.DefaultConfig()
+	cfg.AI.Enabled = true
+	cfg.AI.Endpoint = "http://127.0.0.1:1/v1" // nothing listens here
+	cfg.AI.Model = "llama3"
+	cfg.AI.Cache.Enabled = &disabled
+	cfg.AI.Fallbacks = []cli.AIModelConfig{{Model: "gpt-4o-mini", Endpoint: backup.URL}}
+	cfg.AI.Prices = map[string]cli.AIPrice{"gpt-4o-mini": {Input: 1, Output: 1}}
+
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{{
+		Path:     "main.go",
+		Status:   interfaces.FileModified,
+		Language: "go",
+		Hunks: []interfaces.Hunk{{
+			NewStart:   1,
+			NewLines:   1,
+			Content:    "+package main",
+			AddedLines: []interfaces.Line{{Number: 1, Content: "package main"}},
+		}},
+	}}}
+
+	result, err := runAIReview(context.Background(), cfg, diff, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("runAIReview: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected the review to run on the fallback instead of being skipped")
+	}
+	want := []string{"gpt-4o-mini @ " + backup.URL}
+	if got := result.Metadata["answered_by"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("answered_by = %v, want %v", got, want)
+	}
+	if _, ok := result.Metadata["cost_usd"]; !ok {
+		t.Error("expected the cost to be priced at the fallback model's price")
+	}
+}
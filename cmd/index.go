@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toyinlola/shipsafe/pkg/ai"
+	"github.com/toyinlola/shipsafe/pkg/ai/providers"
+	"github.com/toyinlola/shipsafe/pkg/cli"
+)
+
+var indexRebuild bool
+
+var indexCmd = &cobra.Command{
+	Use:   "index [path]",
+	Short: "Build the embedding index used to retrieve related code for AI review",
+	Long: `Index splits the text files git tracks in the repository, except
+dot-files, into chunks and embeds them through the OpenAI-compatible
+/embeddings endpoint configured under ai.retrieval, storing the vectors in a
+local file (default: .shipsafe/index.gob in the repository). Ignored and
+untracked files are never sent. With ai.retrieval.enabled, AI review adds the
+chunks most similar to each changed hunk to its context.
+
+Re-running index only embeds chunks that changed since the last run.
+
+  shipsafe index .`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runIndex,
+}
+
+func init() {
+	indexCmd.Flags().BoolVar(&indexRebuild, "rebuild", false, "embed every chunk again instead of reusing unchanged ones")
+	rootCmd.AddCommand(indexCmd)
+}
+
+func runIndex(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	cfg, err := cli.LoadConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if cfg.AI.Retrieval.Model == "" {
+		return fmt.Errorf("index: ai.retrieval.model must name an embedding model")
+	}
+	embedCfg := aiEmbeddingConfig(cfg)
+	if embedCfg.Endpoint == "" {
+		return fmt.Errorf("index: set ai.retrieval.endpoint or ai.endpoint")
+	}
+	// Indexing sends the whole repository, so the policy applies as for review.
+	if err := aiEndpointPolicy(cfg).Check(ctx, embedCfg.Endpoint); err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+
+	path := aiIndexPath(cfg, root)
+	var previous *ai.Index
+	if !indexRebuild && fileExists(path) {
+		if previous, err = ai.LoadIndex(path); err != nil {
+			slog.Warn("existing index unreadable, rebuilding", "path", path, "error", err)
+		}
+	}
+
+	slog.Info("indexing repository", "root", root, "endpoint", embedCfg.Endpoint, "model", embedCfg.Model)
+	index, stats, err := ai.BuildIndex(ctx, root, providers.NewOpenAIEmbedder(embedCfg, 0), embedCfg.Model, previous)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if err := ai.SaveIndex(path, index); err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Indexed %d files into %d chunks (%d embedded, %d unchanged): %s\n",
+		stats.Files, stats.Chunks, stats.Embedded, stats.Reused, path)
+	return nil
+}
+
+// aiEmbeddingConfig returns the embeddings endpoint settings, inheriting the
+// endpoint and API key from the review provider when not set.
+func aiEmbeddingConfig(cfg *cli.Config) ai.ProviderConfig {
+	r := cfg.AI.Retrieval
+	endpoint, keyEnv := r.Endpoint, r.APIKeyEnv
+	if endpoint == "" {
+		endpoint = cfg.AI.Endpoint
+	}
+	if keyEnv == "" {
+		keyEnv = cfg.AI.APIKeyEnv
+	}
+	return ai.ProviderConfig{
+		Endpoint: endpoint,
+		Model:    r.Model,
+		APIKey:   os.Getenv(keyEnv),
+		Type:     ai.ProviderOpenAICompatible,
+	}
+}
+
+// aiIndexPath returns the configured index file, or the default one in the
+// repository at repoRoot.
+func aiIndexPath(cfg *cli.Config, repoRoot string) string {
+	if cfg.AI.Retrieval.Index != "" {
+		return cfg.AI.Retrieval.Index
+	}
+	return filepath.Join(repoRoot, ai.DefaultIndexFile)
+}
+
+// aiRetrieval returns the review option that adds related code from the
+// repository index, or nil when retrieval is off or the index is missing or
+// was built with another model.
+func aiRetrieval(cfg *cli.Config, repoRoot string) ai.Option {
+	if !cfg.AI.Retrieval.Enabled {
+		return nil
+	}
+	path := aiIndexPath(cfg, repoRoot)
+	index, err := ai.LoadIndex(path)
+	if err != nil {
+		slog.Warn("AI review: repository index unavailable, reviewing without related code; run shipsafe index", "error", err)
+		return nil
+	}
+	embedCfg := aiEmbeddingConfig(cfg)
+	if index.Model != embedCfg.Model {
+		slog.Warn("AI review: repository index was built with another embedding model, reviewing without related code; run shipsafe index",
+			"index_model", index.Model, "model", embedCfg.Model)
+		return nil
+	}
+	return ai.WithRetrieval(index, providers.NewOpenAIEmbedder(embedCfg, 0), cfg.AI.Retrieval.TopK)
+}
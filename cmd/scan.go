@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/toyinlola/shipsafe/pkg/ai"
@@ -19,7 +21,10 @@ import (
 	"github.com/toyinlola/shipsafe/pkg/vcs"
 )
 
-var diffFile string
+var (
+	diffFile  string
+	noAICache bool
+)
 
 var scanCmd = &cobra.Command{
 	Use:   "scan [path]",
@@ -37,6 +42,7 @@ Scan a directory (compares against git HEAD):
 
 func init() {
 	scanCmd.Flags().StringVar(&diffFile, "diff", "", "path to a unified diff file to analyze")
+	scanCmd.Flags().BoolVar(&noAICache, "no-ai-cache", false, "bypass the on-disk cache of AI review responses")
 	rootCmd.AddCommand(scanCmd)
 }
 
@@ -97,7 +103,15 @@ func runScan(cmd *cobra.Command, args []string) error {
 	}
 
 	// 5. Run AI review if enabled.
-	if aiResult := runAIReview(ctx, cfg, diff); aiResult != nil {
+	repoRoot := "."
+	if diffFile == "" {
+		repoRoot = target
+	}
+	aiResult, err := runAIReview(ctx, cfg, diff, results, repoRoot)
+	if err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+	if aiResult != nil {
 		results = append(results, aiResult)
 	}
 
@@ -178,53 +192,449 @@ func registerAnalyzers(registry *analyzer.Registry, cfg *cli.Config) {
 }
 
 // runAIReview creates an AI reviewer from config and runs it against the diff.
-// Returns nil if AI review is disabled, unavailable, or fails.
-func runAIReview(ctx context.Context, cfg *cli.Config, diff *interfaces.Diff) *interfaces.AnalysisResult {
+// With ai.triage set it also triages the static findings in static, and with
+// ai.test_suggestions it attaches test skeletons to their coverage findings,
+// updating them in place.
+// repoRoot is the checkout used to resolve ai.context_files.
+// Returns a nil result if AI review is disabled, unavailable, or fails; triage
+// and test suggestions still run when only the review itself fails. The only
+// error is an endpoint blocked by ai.sovereignty: the scan fails closed
+// instead of reporting a verdict that silently lacks the AI review.
+func runAIReview(ctx context.Context, cfg *cli.Config, diff *interfaces.Diff, static []*interfaces.AnalysisResult, repoRoot string) (*interfaces.AnalysisResult, error) {
 	if !cfg.AI.Enabled {
 		slog.Debug("AI review disabled, skipping")
-		return nil
+		return nil, nil
 	}
 
 	if cfg.AI.Endpoint == "" || cfg.AI.Model == "" {
 		slog.Warn("AI review enabled but endpoint or model not configured, skipping")
-		return nil
+		return nil, nil
+	}
+
+	policy := aiEndpointPolicy(cfg)
+	endpoints := aiEndpoints(cfg)
+	for _, endpoint := range endpoints {
+		if strings.HasPrefix(endpoint, replayEndpointPrefix) {
+			continue // No network access.
+		}
+		if err := policy.Check(ctx, endpoint); err != nil {
+			slog.Error("AI review blocked by sovereignty policy", "endpoint", endpoint, "policy", policy.String())
+			return nil, fmt.Errorf("AI review: %w", err)
+		}
+	}
+
+	provider, models, caches := newAIFallbackChain(cfg)
+	if provider == nil {
+		slog.Warn("AI review: no provider could be created, skipping", "provider", cfg.AI.Provider)
+		return nil, nil
+	}
+
+	var consensus []ai.ModelProvider
+	for _, m := range cfg.AI.Models {
+		modelCfg := cfg.AI.ForModel(m)
+		p, c, err := newAIProvider(modelCfg)
+		if err != nil {
+			slog.Warn("AI review: consensus model unavailable, skipping review", "model", m.Model, "error", err)
+			return nil, nil
+		}
+		consensus = append(consensus, ai.ModelProvider{Name: m.Model, Provider: p})
+		caches = append(caches, c)
+	}
+
+	passes := customAIPasses(cfg)
+	options := []ai.Option{
+		ai.WithMaxTokenBudget(aiContextBudget(cfg, passes)),
+		ai.WithTokenCounter(aiTokenCounter(cfg)),
+		ai.WithConcurrency(cfg.AI.Concurrency),
+		ai.WithMaxChunks(cfg.AI.MaxChunks),
+		ai.WithRepoRoot(repoRoot),
+		ai.WithCustomPasses(passes...),
+		ai.WithDisabledPasses(cfg.AI.DisabledPasses...),
+		ai.WithFixPatches(cfg.AI.FixPatches),
+		ai.WithWalkthrough(cfg.AI.Walkthrough),
+	}
+	if cfg.AI.LineTolerance != 0 {
+		options = append(options, ai.WithLineTolerance(cfg.AI.LineTolerance))
+	}
+	if len(consensus) > 0 {
+		options = append(options, ai.WithConsensus(cfg.AI.MinAgreement, consensus...))
+	}
+	if cfg.AI.Agentic.Enabled {
+		options = append(options, ai.WithAgenticTools(cfg.AI.Agentic.MaxTurns, cfg.AI.Agentic.Passes...))
+	}
+	if retrieval := aiRetrieval(cfg, repoRoot); retrieval != nil {
+		options = append(options, retrieval)
+	}
+	if len(cfg.AI.LanguagePrompts) > 0 {
+		packs := make(map[string]ai.LanguagePack, len(cfg.AI.LanguagePrompts))
+		for lang, pack := range cfg.AI.LanguagePrompts {
+			packs[lang] = pack
+		}
+		options = append(options, ai.WithLanguagePacks(packs))
+	}
+	reviewer := ai.NewReviewer(provider, options...)
+
+	if !reviewer.Available(ctx) {
+		slog.Warn("AI review: no LLM endpoint reachable, skipping", "endpoints", endpoints)
+		if cfg.AI.Triage || cfg.AI.TestSuggestions {
+			slog.Warn("AI triage and test suggestions skipped: no LLM endpoint reachable")
+		}
+		return nil, nil
+	}
+
+	slog.Info("running AI review", "endpoint", cfg.AI.Endpoint, "models", aiModelNames(cfg))
+
+	result, err := reviewer.Review(ctx, diff, &interfaces.AIReviewOptions{
+		ContextFiles: cfg.AI.ContextFiles,
+		FocusAreas:   cfg.AI.FocusAreas,
+	})
+	if err != nil {
+		slog.Error("AI review failed", "error", err)
+		// Triage and test suggestions only need the static findings.
+		runAIStaticSteps(ctx, cfg, reviewer, diff, static, make(map[string]any))
+		return nil, nil
 	}
 
-	apiKey := os.Getenv(cfg.AI.APIKeyEnv)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	runAIStaticSteps(ctx, cfg, reviewer, diff, static, result.Metadata)
+	cached, hits, misses := false, 0, 0
+	for _, c := range caches {
+		if c == nil {
+			continue
+		}
+		h, m := c.Stats()
+		cached, hits, misses = true, hits+h, misses+m
+	}
+	if cached {
+		result.Metadata["cache_hits"] = hits
+		result.Metadata["cache_misses"] = misses
+	}
+	result.Metadata["endpoints"] = endpoints
+	if policy.Enabled() {
+		result.Metadata["endpoint_policy"] = policy.String()
+	}
+	if answered := provider.Answered(); len(answered) > 0 {
+		result.Metadata["answered_by"] = answered
+	}
+	answeredUsage := make(map[string]ai.Usage)
+	for name, u := range provider.Usage() {
+		answeredUsage[models[name]] = answeredUsage[models[name]].Add(u)
+	}
+	if cost, ok := aiReviewCost(cfg, result, answeredUsage); ok {
+		result.Metadata["cost_usd"] = cost
+	}
 
+	slog.Info("AI review complete", "findings", len(result.Findings), "duration", result.Duration)
+	return result, nil
+}
+
+// runAIStaticSteps runs the optional AI steps on the static findings, triage
+// and test suggestions, updating them in place and recording counts and token
+// usage in metadata.
+func runAIStaticSteps(ctx context.Context, cfg *cli.Config, reviewer *ai.Reviewer, diff *interfaces.Diff, static []*interfaces.AnalysisResult, metadata map[string]any) {
+	if cfg.AI.Triage {
+		triage := reviewer.Triage(ctx, diff, static)
+		slog.Info("AI triage of static findings complete", "triaged", triage.Triaged,
+			"confirmed", triage.Confirmed, "disputed", triage.Disputed, "failed", triage.Failed)
+		metadata["triaged_findings"] = triage.Triaged
+		metadata["disputed_findings"] = triage.Disputed
+		addAIUsage(metadata, "triage", triage.Usage)
+	}
+	if cfg.AI.TestSuggestions {
+		tests := reviewer.SuggestTests(ctx, diff, static)
+		slog.Info("AI test suggestions complete", "suggested", tests.Suggested, "failed", tests.Failed)
+		metadata["test_suggestions"] = tests.Suggested
+		addAIUsage(metadata, "tests", tests.Usage)
+	}
+}
+
+// addAIUsage adds tokens spent outside the review passes to the review
+// result's totals and records them under pass in its per-pass usage.
+func addAIUsage(metadata map[string]any, pass string, u ai.Usage) {
+	prompt, _ := metadata["prompt_tokens"].(int)
+	completion, _ := metadata["completion_tokens"].(int)
+	metadata["prompt_tokens"] = prompt + u.PromptTokens
+	metadata["completion_tokens"] = completion + u.CompletionTokens
+
+	passUsage, _ := metadata["pass_usage"].(map[string]interfaces.TokenUsage)
+	if passUsage == nil {
+		passUsage = make(map[string]interfaces.TokenUsage)
+		metadata["pass_usage"] = passUsage
+	}
+	pu := passUsage[pass]
+	pu.PromptTokens += u.PromptTokens
+	pu.CompletionTokens += u.CompletionTokens
+	passUsage[pass] = pu
+}
+
+// aiEndpointPolicy converts the configured sovereignty policy.
+func aiEndpointPolicy(cfg *cli.Config) ai.EndpointPolicy {
+	return ai.EndpointPolicy{
+		AllowedHosts: cfg.AI.Sovereignty.AllowedHosts,
+		PrivateOnly:  cfg.AI.Sovereignty.PrivateOnly,
+	}
+}
+
+// replayEndpointPrefix marks a cassette in the list of review endpoints.
+const replayEndpointPrefix = "replay:"
+
+// aiEndpoints returns the distinct endpoints a review may send prompts to,
+// including consensus models, fallbacks and the embeddings endpoint used for
+// retrieval.
+func aiEndpoints(cfg *cli.Config) []string {
+	configs := []cli.AIConfig{cfg.AI}
+	for _, m := range cfg.AI.Fallbacks {
+		configs = append(configs, cfg.AI.ForModel(m))
+	}
+	for _, m := range cfg.AI.Models {
+		configs = append(configs, cfg.AI.ForModel(m))
+	}
+
+	var endpoints []string
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		endpoint := aiEndpoint(c)
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if cfg.AI.Retrieval.Enabled {
+		if endpoint := aiEmbeddingConfig(cfg).Endpoint; !seen[endpoint] {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// aiEndpoint returns the endpoint c sends prompts to. Replay providers make
+// no requests; their cassette is returned instead, so the report still shows
+// where responses came from.
+func aiEndpoint(c cli.AIConfig) string {
+	if ai.ProviderType(c.Provider) == ai.ProviderReplay {
+		return replayEndpointPrefix + c.Cassette
+	}
+	return c.Endpoint
+}
+
+// newAIFallbackChain builds the configured provider followed by its
+// fallbacks. It also returns the model behind each member name, for pricing,
+// and the members' response caches. Members that cannot be built are left
+// out; the provider is nil if none can.
+func newAIFallbackChain(cfg *cli.Config) (*providers.FallbackProvider, map[string]string, []*providers.CacheProvider) {
+	configs := []cli.AIConfig{cfg.AI}
+	for _, m := range cfg.AI.Fallbacks {
+		configs = append(configs, cfg.AI.ForModel(m))
+	}
+
+	var members []ai.ModelProvider
+	var caches []*providers.CacheProvider
+	models := make(map[string]string)
+	for _, c := range configs {
+		name := c.Model + " @ " + aiEndpoint(c)
+		if _, dup := models[name]; dup {
+			continue
+		}
+		p, cache, err := newAIProvider(c)
+		if err != nil {
+			slog.Warn("AI review: provider unavailable", "provider", name, "error", err)
+			continue
+		}
+		members = append(members, ai.ModelProvider{Name: name, Provider: p})
+		caches = append(caches, cache)
+		models[name] = c.Model
+	}
+	if len(members) == 0 {
+		return nil, nil, nil
+	}
+	return providers.NewFallbackProvider(members...), models, caches
+}
+
+// newAIProvider builds the provider chain from config: the protocol client,
+// wrapped with retries, the response cache (returned separately so its stats
+// can be reported) and, if configured, a cassette recorder. The replay
+// provider is returned unwrapped.
+func newAIProvider(aiCfg cli.AIConfig) (ai.LLMProvider, *providers.CacheProvider, error) {
 	providerCfg := ai.ProviderConfig{
-		Endpoint: cfg.AI.Endpoint,
-		Model:    cfg.AI.Model,
-		APIKey:   apiKey,
-		Type:     ai.ProviderType(cfg.AI.Provider),
+		Endpoint: aiCfg.Endpoint,
+		Model:    aiCfg.Model,
+		APIKey:   os.Getenv(aiCfg.APIKeyEnv),
+		Type:     ai.ProviderType(aiCfg.Provider),
 	}
 
 	var provider ai.LLMProvider
-	switch ai.ProviderType(cfg.AI.Provider) {
+	switch ai.ProviderType(aiCfg.Provider) {
 	case ai.ProviderOpenAICompatible, "":
-		provider = providers.NewOpenAIProvider(providerCfg, 0)
+		if aiCfg.Stream {
+			provider = providers.NewStreamingOpenAIProvider(providerCfg, aiCfg.StreamIdleTimeout)
+		} else {
+			provider = providers.NewOpenAIProvider(providerCfg, 0)
+		}
+	case ai.ProviderAnthropic:
+		provider = providers.NewAnthropicProvider(providerCfg, 0)
+	case ai.ProviderReplay:
+		if aiCfg.Cassette == "" {
+			return nil, nil, fmt.Errorf("ai.cassette must be set for the replay provider")
+		}
+		replay, err := providers.NewReplayProvider(aiCfg.Cassette)
+		if err != nil {
+			return nil, nil, err
+		}
+		return replay, nil, nil
 	default:
-		slog.Warn("AI review: unsupported provider, skipping", "provider", cfg.AI.Provider)
-		return nil
+		return nil, nil, fmt.Errorf("unsupported provider %q", aiCfg.Provider)
 	}
 
-	reviewer := ai.NewReviewer(provider)
+	provider = providers.NewRetryProvider(provider, providers.RetryConfig{
+		MaxRetries:        aiCfg.MaxRetries,
+		InitialBackoff:    aiCfg.InitialBackoff,
+		MaxBackoff:        aiCfg.MaxBackoff,
+		RequestsPerMinute: aiCfg.RequestsPerMinute,
+		TokensPerMinute:   aiCfg.TokensPerMinute,
+	})
+
+	var cache *providers.CacheProvider
+	if aiCfg.Cache.IsEnabled() && !noAICache {
+		c, err := providers.NewCacheProvider(provider, providers.CacheConfig{
+			Dir:     aiCfg.Cache.Dir,
+			Model:   aiCfg.Model,
+			TTL:     aiCfg.Cache.TTL,
+			MaxSize: int64(aiCfg.Cache.MaxSizeMB) << 20,
+		})
+		if err != nil {
+			slog.Warn("AI review: response cache unavailable, continuing without it", "error", err)
+		} else {
+			cache = c
+			provider = c
+		}
+	}
 
-	if !reviewer.Available(ctx) {
-		slog.Warn("AI review: LLM endpoint unreachable, skipping", "endpoint", cfg.AI.Endpoint)
-		return nil
+	if aiCfg.RecordCassette != "" {
+		recorder, err := providers.NewRecordingProvider(provider, aiCfg.RecordCassette)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("AI review: recording interactions", "cassette", aiCfg.RecordCassette)
+		provider = recorder
 	}
 
-	slog.Info("running AI review", "endpoint", cfg.AI.Endpoint, "model", cfg.AI.Model)
+	return provider, cache, nil
+}
 
-	result, err := reviewer.Review(ctx, diff, nil)
+// aiContextBudget derives the diff context budget from the model's context
+// window, leaving room for the largest completion any pass may request.
+func aiContextBudget(cfg *cli.Config, passes []ai.PassConfig) int {
+	window := cfg.AI.ContextWindow
+	if window <= 0 {
+		// Every model sees the same prompts, so the smallest window decides.
+		for i, model := range aiModelNames(cfg) {
+			w := ai.ContextWindow(model)
+			if w <= 0 {
+				slog.Debug("AI review: unknown model context window, using default budget", "model", model)
+				window = 0
+				break
+			}
+			if i == 0 || w < window {
+				window = w
+			}
+		}
+	}
+
+	maxOutput := ai.DefaultPassMaxTokens
+	for _, p := range passes {
+		if p.MaxTokens > maxOutput {
+			maxOutput = p.MaxTokens
+		}
+	}
+
+	budget := ai.ContextBudget(window, maxOutput)
+	if cfg.AI.MaxContextTokens > 0 && budget > cfg.AI.MaxContextTokens {
+		budget = cfg.AI.MaxContextTokens
+	}
+	return budget
+}
+
+// aiTokenCounter loads the configured tokenizer vocabulary, falling back to
+// the chars-per-token heuristic when none is configured or it cannot be read.
+func aiTokenCounter(cfg *cli.Config) ai.TokenCounter {
+	if cfg.AI.TokenizerFile == "" {
+		return ai.HeuristicCounter{}
+	}
+	counter, err := ai.LoadBPECounter(cfg.AI.TokenizerFile)
 	if err != nil {
-		slog.Error("AI review failed", "error", err)
-		return nil
+		slog.Warn("AI review: tokenizer unavailable, estimating tokens", "error", err)
+		return ai.HeuristicCounter{}
 	}
+	return counter
+}
 
-	slog.Info("AI review complete", "findings", len(result.Findings), "duration", result.Duration)
-	return result
+// aiReviewCost prices the review's token usage with the configured price table.
+// Consensus models' usage is priced per model, and the rest by the model of
+// the provider or fallback that answered (answered, keyed by model). The cost
+// is only reported if every model used has a price.
+func aiReviewCost(cfg *cli.Config, result *interfaces.AnalysisResult, answered map[string]ai.Usage) (float64, bool) {
+	prices := make(map[string]ai.Price, len(cfg.AI.Prices))
+	for model, p := range cfg.AI.Prices {
+		prices[model] = ai.Price{Input: p.Input, Output: p.Output}
+	}
+	usage := make(map[string]ai.Usage, len(answered))
+	for model, u := range answered {
+		usage[model] = u
+	}
+	modelUsage, _ := result.Metadata["model_usage"].(map[string]interfaces.TokenUsage)
+	for model, u := range modelUsage {
+		usage[model] = usage[model].Add(ai.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens})
+	}
+	if len(usage) == 0 {
+		usage[cfg.AI.Model] = ai.Usage{}
+	}
+
+	cost := 0.0
+	for model, u := range usage {
+		p, ok := ai.LookupPrice(prices, model)
+		if !ok {
+			return 0, false
+		}
+		cost += p.Cost(u)
+	}
+	return cost, true
+}
+
+// aiModelNames returns the models a review may send its passes to: the
+// consensus models when configured, otherwise the configured model and its
+// fallbacks.
+func aiModelNames(cfg *cli.Config) []string {
+	if len(cfg.AI.Models) == 0 {
+		names := []string{cfg.AI.Model}
+		for _, m := range cfg.AI.Fallbacks {
+			if model := cfg.AI.ForModel(m).Model; !slices.Contains(names, model) {
+				names = append(names, model)
+			}
+		}
+		return names
+	}
+	names := make([]string, len(cfg.AI.Models))
+	for i, m := range cfg.AI.Models {
+		names[i] = m.Model
+	}
+	return names
+}
+
+// customAIPasses converts configured custom passes to reviewer pass definitions.
+func customAIPasses(cfg *cli.Config) []ai.PassConfig {
+	passes := make([]ai.PassConfig, 0, len(cfg.AI.Passes))
+	for _, p := range cfg.AI.Passes {
+		passes = append(passes, ai.PassConfig{
+			Name:         p.Name,
+			Category:     interfaces.Category(p.Category),
+			SystemPrompt: p.SystemPrompt,
+			Temperature:  p.Temperature,
+			MaxTokens:    p.MaxTokens,
+		})
+	}
+	return passes
 }
 
 // selectFormatter returns the appropriate report formatter for the given format name.
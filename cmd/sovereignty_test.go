@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+	"github.com/toyinlola/shipsafe/pkg/cli"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func TestRunAIReview_SovereigntyPolicyFailsClosed(t *testing.T) {
+	cfg := cli.DefaultConfig()
+	cfg.AI.Enabled = true
+	cfg.AI.Endpoint = "https://203.0.113.10/v1"
+	cfg.AI.Model = "gpt-4o-mini"
+	cfg.AI.Sovereignty.PrivateOnly = true
+
+	result, err := runAIReview(context.Background(), cfg, &interfaces.Diff{}, nil, ".")
+	if !errors.Is(err, ai.ErrEndpointBlocked) {
+		t.Fatalf("expected the review to fail closed with ErrEndpointBlocked, got %v", err)
+	}
+	if result != nil {
+		t.Error("expected no AI result when the endpoint is blocked")
+	}
+}
+
+func TestRunAIReview_SovereigntyPolicyChecksConsensusModels(t *testing.T) {
+	cfg := cli.DefaultConfig()
+	cfg.AI.Enabled = true
+	cfg.AI.Endpoint = "http://127.0.0.1:1/v1"
+	cfg.AI.Model = "llama3"
+	cfg.AI.Models = []cli.AIModelConfig{
+		{Model: "llama3"},
+		{Model: "gpt-4o-mini", Endpoint: "https://203.0.113.10/v1"},
+	}
+	cfg.AI.Sovereignty.AllowedHosts = []string{"127.0.0.0/8"}
+
+	if _, err := runAIReview(context.Background(), cfg, &interfaces.Diff{}, nil, "."); !errors.Is(err, ai.ErrEndpointBlocked) {
+		t.Fatalf("expected a blocked consensus endpoint to fail the review, got %v", err)
+	}
+}
+
+func TestAIEndpoints(t *testing.T) {
+	cfg := cli.DefaultConfig()
+	cfg.AI.Endpoint = "http://ollama:11434/v1"
+	cfg.AI.Models = []cli.AIModelConfig{
+		{Model: "llama3"},
+		{Model: "claude-3-5-haiku-latest", Provider: "anthropic"},
+	}
+	cfg.AI.Fallbacks = []cli.AIModelConfig{{Endpoint: "http://backup:11434/v1"}}
+
+	got := aiEndpoints(cfg)
+	want := []string{"http://ollama:11434/v1", "http://backup:11434/v1", "https://api.anthropic.com/v1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aiEndpoints() = %v, want %v", got, want)
+	}
+}
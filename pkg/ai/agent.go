@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+)
+
+// DefaultAgentTurns is the number of tool rounds an agentic pass may use when
+// WithAgenticTools is given no positive limit.
+const DefaultAgentTurns = 4
+
+// agentMaxCallsPerTurn bounds the tool calls run for one model turn; further
+// calls in the same turn are answered with an error.
+const agentMaxCallsPerTurn = 8
+
+// WithAgenticTools lets review passes call read-only repository tools
+// (read_file, grep, list_dir), sandboxed to the repository root, for up to
+// maxTurns rounds before giving their findings. Only the named passes are
+// agentic; all passes are when none are named. Providers that cannot call
+// tools run the passes as usual.
+func WithAgenticTools(maxTurns int, passes ...string) Option {
+	return func(r *Reviewer) {
+		if maxTurns <= 0 {
+			maxTurns = DefaultAgentTurns
+		}
+		r.agentTurns = maxTurns
+		r.agentPasses = nil
+		for _, p := range passes {
+			if r.agentPasses == nil {
+				r.agentPasses = make(map[string]bool)
+			}
+			r.agentPasses[strings.ToLower(strings.TrimSpace(p))] = true
+		}
+	}
+}
+
+// agentic reports whether the named pass may call tools.
+func (r *Reviewer) agentic(passName string) bool {
+	return r.agentTurns > 0 && (r.agentPasses == nil || r.agentPasses[passName])
+}
+
+// runAgentPass runs a review pass as a tool-calling conversation. The model
+// may request tools for up to r.agentTurns rounds; after that it is asked
+// for its findings without tools. Tool output is fenced as untrusted and
+// counts against the pass's context budget; once that is spent, tool calls
+// are refused. Returns the final response with the usage summed over all
+// turns, and the number of tool calls run.
+func (r *Reviewer) runAgentPass(ctx context.Context, pass reviewPass, provider LLMProvider) (Completion, int, error) {
+	tools := newRepoTools(ctx, r.repoRoot)
+	definitions := tools.definitions()
+	opts := CompletionOpts{
+		MaxTokens:    pass.maxTokens,
+		Temperature:  pass.temperature,
+		SystemPrompt: pass.systemPrompt + prompts.AgentInstructions(r.agentTurns),
+	}
+	messages := []Message{{Role: "user", Content: pass.userPrompt}}
+	remaining := pass.budget - r.counter.CountTokens(pass.userPrompt)
+
+	var usage Usage
+	calls := 0
+	for turn := 0; ; turn++ {
+		offered := definitions
+		if turn == r.agentTurns {
+			offered = nil
+			messages = append(messages, Message{Role: "user", Content: prompts.AgentFinalTurn})
+		}
+
+		resp, err := CompleteWithTools(ctx, provider, messages, offered, opts)
+		usage = usage.Add(resp.Usage)
+		if err != nil {
+			return Completion{Usage: usage}, calls, err
+		}
+		if len(resp.ToolCalls) == 0 || offered == nil {
+			return Completion{Text: resp.Text, Usage: usage}, calls, nil
+		}
+
+		messages = append(messages, Message{Role: "assistant", Content: resp.Text, ToolCalls: resp.ToolCalls})
+		for i, call := range resp.ToolCalls {
+			output := "error: too many tool calls in one round"
+			if i < agentMaxCallsPerTurn {
+				slog.Debug("AI review tool call", "pass", pass.name, "tool", call.Name, "arguments", call.Arguments)
+				output = prompts.FenceUntrusted(tools.call(call.Name, call.Arguments))
+				calls++
+				if n := r.counter.CountTokens(output); n > remaining {
+					output = "error: the output does not fit the remaining context budget; request less, or answer with what you have"
+				} else {
+					remaining -= n
+				}
+			}
+			messages = append(messages, Message{Role: "tool", ToolCallID: call.ID, Content: output})
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// toolProvider answers tool-calling requests with respond, given the
+// conversation so far and whether tools were offered.
+type toolProvider struct {
+	mu      sync.Mutex
+	respond func(messages []Message, tools []Tool) ToolCompletion
+	plain   int
+}
+
+func (p *toolProvider) Complete(_ context.Context, _ string, _ CompletionOpts) (Completion, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.plain++
+	return Completion{Text: `{"findings": []}`}, nil
+}
+
+func (p *toolProvider) CompleteWithTools(_ context.Context, messages []Message, tools []Tool, _ CompletionOpts) (ToolCompletion, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.respond(messages, tools), nil
+}
+
+func (p *toolProvider) Available(_ context.Context) bool { return true }
+
+func TestReviewer_Review_AgenticPassReadsRepository(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "pkg/lock.go"), "package pkg\n\nfunc Unlock() {\n\t// releases twice\n}\n")
+
+	var toolResult string
+	provider := &toolProvider{respond: func(messages []Message, tools []Tool) ToolCompletion {
+		last := messages[len(messages)-1]
+		if last.Role != "tool" {
+			return ToolCompletion{
+				Completion: Completion{Usage: Usage{PromptTokens: 100, CompletionTokens: 10}},
+				ToolCalls:  []ToolCall{{ID: "c1", Name: "read_file", Arguments: `{"path": "pkg/lock.go"}`}},
+			}
+		}
+		toolResult = last.Content
+		return ToolCompletion{Completion: Completion{
+			Text:  `{"findings": [{"file": "main.go", "line": 5, "severity": "high", "title": "Double unlock", "description": "Unlock releases the mutex twice."}]}`,
+			Usage: Usage{PromptTokens: 200, CompletionTokens: 20},
+		}}
+	}}
+
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5)}}
+	result, err := NewReviewer(provider, WithRepoRoot(root), WithAgenticTools(3, "logic")).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	if !strings.Contains(toolResult, "4 | \t// releases twice") || !strings.Contains(toolResult, "BEGIN UNTRUSTED") {
+		t.Errorf("expected the fenced file content as the tool result, got %q", toolResult)
+	}
+	if provider.plain != 2 {
+		t.Errorf("expected the non-agentic passes to run without tools, got %d plain calls", provider.plain)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Title != "Double unlock" {
+		t.Fatalf("expected the agentic pass's finding, got %+v", result.Findings)
+	}
+	if result.Metadata["tool_calls"] != 1 {
+		t.Errorf("expected 1 tool call recorded, got %v", result.Metadata["tool_calls"])
+	}
+	if usage := result.Metadata["pass_usage"].(map[string]interfaces.TokenUsage)["logic"]; usage.PromptTokens != 300 {
+		t.Errorf("expected usage summed over the agent's turns, got %+v", usage)
+	}
+}
+
+func TestReviewer_Review_AgenticPassIsBounded(t *testing.T) {
+	turns := 0
+	var finalTools []Tool
+	provider := &toolProvider{respond: func(_ []Message, tools []Tool) ToolCompletion {
+		turns++
+		finalTools = tools
+		if tools == nil {
+			return ToolCompletion{Completion: Completion{Text: `{"findings": []}`}}
+		}
+		return ToolCompletion{ToolCalls: []ToolCall{{ID: "c", Name: "list_dir", Arguments: `{}`}}}
+	}}
+
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5)}}
+	result, err := NewReviewer(provider, WithRepoRoot(t.TempDir()), WithAgenticTools(2, "semantic")).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if turns != 3 || finalTools != nil {
+		t.Errorf("expected 2 tool rounds and a final turn without tools, got %d turns", turns)
+	}
+	if result.Metadata["tool_calls"] != 2 {
+		t.Errorf("expected 2 tool calls, got %v", result.Metadata["tool_calls"])
+	}
+}
+
+func TestReviewer_Review_AgenticFallsBackWithoutToolSupport(t *testing.T) {
+	provider := &mockProvider{available: true, responses: []string{`{"findings": []}`, `{"findings": []}`, `{"findings": []}`}}
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5)}}
+
+	result, err := NewReviewer(provider, WithAgenticTools(0)).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if provider.callIndex != 3 {
+		t.Errorf("expected every pass to run without tools, got %d calls", provider.callIndex)
+	}
+	if result.Metadata["tool_calls"] != 0 {
+		t.Errorf("expected no tool calls, got %v", result.Metadata["tool_calls"])
+	}
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+	"sort"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// ModelProvider is a provider and the name it is reported under: one member
+// of a consensus review or of a fallback chain.
+type ModelProvider struct {
+	Name     string
+	Provider LLMProvider
+}
+
+// WithConsensus runs every review pass against each of the given models and
+// keeps only findings that at least minAgreement of them report. Agreement is
+// decided with IsDuplicate, and a kept finding's confidence is the fraction
+// of models that reported it. minAgreement <= 0 means a strict majority.
+// Fix patches are still requested from the reviewer's own provider.
+func WithConsensus(minAgreement int, models ...ModelProvider) Option {
+	return func(r *Reviewer) {
+		r.consensus = append(r.consensus, models...)
+		r.minAgreement = minAgreement
+	}
+}
+
+// consensusAgreement returns the number of models that must agree, clamped to
+// the number of models.
+func (r *Reviewer) consensusAgreement() int {
+	n := r.minAgreement
+	if n <= 0 {
+		n = len(r.consensus)/2 + 1
+	}
+	if n > len(r.consensus) {
+		n = len(r.consensus)
+	}
+	return n
+}
+
+// consensusAvailable reports whether enough consensus models are reachable
+// for agreement to be possible.
+func (r *Reviewer) consensusAvailable(ctx context.Context) bool {
+	up := 0
+	for _, m := range r.consensus {
+		if m.Provider.Available(ctx) {
+			up++
+		}
+	}
+	return up >= r.consensusAgreement()
+}
+
+// applyConsensus groups findings that describe the same issue and keeps one
+// finding per group reported by at least minAgreement distinct models. Each
+// finding must carry the reporting model in Metadata["model"]. The kept
+// finding is the most severe in its group (the earliest on ties); its
+// confidence is the share of models that agreed and Metadata["models"] lists
+// them. Returns the kept findings and the number of groups rejected.
+func applyConsensus(findings []interfaces.Finding, models, minAgreement int) ([]interfaces.Finding, int) {
+	assigned := make([]bool, len(findings))
+	kept := make([]interfaces.Finding, 0, len(findings))
+	rejected := 0
+
+	for i := range findings {
+		if assigned[i] {
+			continue
+		}
+		assigned[i] = true
+		best := i
+		agreeing := map[string]bool{findingModel(findings[i]): true}
+
+		for j := i + 1; j < len(findings); j++ {
+			if assigned[j] || !IsDuplicate(findings[i], findings[j]) {
+				continue
+			}
+			assigned[j] = true
+			agreeing[findingModel(findings[j])] = true
+			if severityRank(findings[j].Severity) > severityRank(findings[best].Severity) {
+				best = j
+			}
+		}
+
+		if len(agreeing) < minAgreement {
+			rejected++
+			continue
+		}
+
+		f := findings[best]
+		names := make([]string, 0, len(agreeing))
+		for name := range agreeing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		meta := make(map[string]any, len(f.Metadata)+1)
+		for k, v := range f.Metadata {
+			meta[k] = v
+		}
+		meta["models"] = names
+		f.Metadata = meta
+
+		f.Confidence = float64(len(agreeing)) / float64(models)
+		if _, moved := meta["reported_line"]; moved {
+			f.Confidence *= adjustedConfidenceFactor
+		}
+		kept = append(kept, f)
+	}
+	return kept, rejected
+}
+
+// findingModel returns the model that reported f.
+func findingModel(f interfaces.Finding) string {
+	name, _ := f.Metadata["model"].(string)
+	return name
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func modelFinding(model, title, description string, line int, sev interfaces.Severity) interfaces.Finding {
+	return interfaces.Finding{
+		File:        "main.go",
+		StartLine:   line,
+		Severity:    sev,
+		Title:       title,
+		Description: description,
+		Confidence:  0.7,
+		Metadata:    map[string]any{"model": model},
+	}
+}
+
+func TestApplyConsensus(t *testing.T) {
+	findings := []interfaces.Finding{
+		modelFinding("llama", "Unchecked error", "Error from Decode is ignored before use", 10, interfaces.SeverityMedium),
+		modelFinding("qwen", "Ignored error", "Error from Decode is ignored before use", 12, interfaces.SeverityHigh),
+		modelFinding("llama", "Odd naming", "Variable name does not follow conventions", 40, interfaces.SeverityLow),
+		// The same model reporting an issue twice is still one vote.
+		modelFinding("llama", "Odd naming again", "Variable name does not follow conventions here", 41, interfaces.SeverityLow),
+	}
+
+	kept, rejected := applyConsensus(findings, 3, 2)
+	if len(kept) != 1 || rejected != 1 {
+		t.Fatalf("expected 1 kept and 1 rejected, got %d kept (%+v) and %d rejected", len(kept), kept, rejected)
+	}
+
+	f := kept[0]
+	if f.Severity != interfaces.SeverityHigh || f.Title != "Ignored error" {
+		t.Errorf("expected the most severe finding of the group to be kept, got %q (%s)", f.Title, f.Severity)
+	}
+	if want := 2.0 / 3.0; f.Confidence != want {
+		t.Errorf("expected confidence %v from 2 of 3 models, got %v", want, f.Confidence)
+	}
+	if models := f.Metadata["models"]; !reflect.DeepEqual(models, []string{"llama", "qwen"}) {
+		t.Errorf("expected agreeing models in metadata, got %v", models)
+	}
+	if _, ok := findings[1].Metadata["models"]; ok {
+		t.Error("applyConsensus must not modify the input findings' metadata")
+	}
+}
+
+func TestApplyConsensus_MovedFindingLowersConfidence(t *testing.T) {
+	a := modelFinding("a", "Leak", "File handle never closed after open", 10, interfaces.SeverityHigh)
+	a.Metadata["reported_line"] = 8
+	b := modelFinding("b", "Leak", "File handle never closed after open", 10, interfaces.SeverityHigh)
+
+	kept, _ := applyConsensus([]interfaces.Finding{a, b}, 2, 2)
+	if len(kept) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(kept))
+	}
+	if want := 1.0 * adjustedConfidenceFactor; kept[0].Confidence != want {
+		t.Errorf("expected confidence %v for a moved finding, got %v", want, kept[0].Confidence)
+	}
+}
+
+func TestReviewer_Review_Consensus(t *testing.T) {
+	shared := `{"findings": [{"file": "main.go", "line": 10, "severity": "high", "title": "SQL injection", "description": "User input concatenated into the SQL query string."}]}`
+	llama := &mockProvider{available: true, responses: []string{
+		shared,
+		`{"findings": [{"file": "main.go", "line": 11, "severity": "low", "title": "Magic number", "description": "Timeout literal should be a named constant."}]}`,
+	}}
+	qwen := &mockProvider{available: true, responses: []string{shared}}
+	primary := &mockProvider{available: false}
+
+	r := NewReviewer(primary, WithConsensus(0,
+		ModelProvider{Name: "llama3", Provider: llama},
+		ModelProvider{Name: "qwen2.5-coder", Provider: qwen},
+	))
+	if !r.Available(context.Background()) {
+		t.Fatal("expected consensus reviewer to be available when its models are")
+	}
+
+	result, err := r.Review(context.Background(),
+		&interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 10, 11)}}, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	if len(result.Findings) != 1 || result.Findings[0].Title != "SQL injection" {
+		t.Fatalf("expected only the agreed finding, got %+v", result.Findings)
+	}
+	if result.Findings[0].Confidence != 1.0 {
+		t.Errorf("expected full confidence when both models agree, got %v", result.Findings[0].Confidence)
+	}
+	if llama.callIndex == 0 || qwen.callIndex == 0 || primary.callIndex != 0 {
+		t.Errorf("expected passes sent to the consensus models only, got calls llama=%d qwen=%d primary=%d",
+			llama.callIndex, qwen.callIndex, primary.callIndex)
+	}
+	if result.Metadata["consensus_rejected"] != 1 || result.Metadata["consensus_min_agreement"] != 2 {
+		t.Errorf("unexpected consensus metadata: rejected=%v min=%v",
+			result.Metadata["consensus_rejected"], result.Metadata["consensus_min_agreement"])
+	}
+}
+
+func TestReviewer_Available_ConsensusNeedsEnoughModels(t *testing.T) {
+	r := NewReviewer(&mockProvider{available: true}, WithConsensus(2,
+		ModelProvider{Name: "a", Provider: &mockProvider{available: true}},
+		ModelProvider{Name: "b", Provider: &mockProvider{available: false}},
+		ModelProvider{Name: "c", Provider: &mockProvider{available: false}},
+	))
+	if r.Available(context.Background()) {
+		t.Error("expected reviewer to be unavailable when fewer than min_agreement models are reachable")
+	}
+}
@@ -1,7 +1,12 @@
 package ai
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -21,18 +26,124 @@ var securitySensitivePatterns = []string{
 	"oauth", "jwt", "cert", "ssl", "tls",
 }
 
-// BuildContext creates a concise context string from a Diff for LLM consumption.
-// It respects the given maxTokenBudget (in tokens, estimated at 4 chars/token).
-// If the diff is too large, security-sensitive files are prioritized.
-func BuildContext(diff *interfaces.Diff, maxTokenBudget int) string {
+// EstimateTokens returns a rough token count for s using the chars-per-token heuristic.
+func EstimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// DefaultMaxChunks bounds how many context chunks a single review may produce.
+const DefaultMaxChunks = 8
+
+// ContextChunk is one budget-sized slice of the diff context. Every chunk
+// repeats the PR header so each LLM call sees the stated intent.
+type ContextChunk struct {
+	Text      string   // Context text ready to embed in a prompt
+	Files     []string // Paths of the files included in this chunk
+	Languages []string // Distinct languages of those files, in first-seen order
+}
+
+// ChunkPlan is the result of splitting a diff into context chunks.
+type ChunkPlan struct {
+	Chunks  []ContextChunk
+	Skipped []string // Files left out because of binary content or the chunk limit
+}
+
+// ReviewedFiles returns the paths of all files included in any chunk.
+func (p ChunkPlan) ReviewedFiles() []string {
+	var files []string
+	for _, c := range p.Chunks {
+		files = append(files, c.Files...)
+	}
+	return files
+}
+
+// BuildContextChunks splits a diff into file-grouped chunks that each fit
+// within maxTokenBudget as measured by counter (nil uses the chars-per-token
+// heuristic). Files are packed in priority order (security-sensitive first,
+// then largest), a file larger than the whole budget is truncated into a chunk
+// of its own, and files that do not fit once maxChunks chunks exist are
+// reported as skipped.
+func BuildContextChunks(diff *interfaces.Diff, counter TokenCounter, maxTokenBudget, maxChunks int) ChunkPlan {
+	if counter == nil {
+		counter = HeuristicCounter{}
+	}
 	if maxTokenBudget <= 0 {
 		maxTokenBudget = DefaultMaxTokenBudget
 	}
-	maxChars := maxTokenBudget * charsPerToken
+	if maxChunks <= 0 {
+		maxChunks = DefaultMaxChunks
+	}
+
+	header := buildContextHeader(diff)
+	const truncMarker = "\n... (file truncated)\n"
+	markerTokens := counter.CountTokens(truncMarker)
+	available := maxTokenBudget - counter.CountTokens(header)
+	if available < markerTokens+25 {
+		available = markerTokens + 25
+	}
+
+	var plan ChunkPlan
+	var current strings.Builder
+	var currentFiles, currentLanguages []string
+	currentTokens := 0
+
+	flush := func() {
+		if len(currentFiles) == 0 {
+			return
+		}
+		plan.Chunks = append(plan.Chunks, ContextChunk{
+			Text:      header + current.String(),
+			Files:     currentFiles,
+			Languages: currentLanguages,
+		})
+		current.Reset()
+		currentFiles, currentLanguages = nil, nil
+		currentTokens = 0
+	}
+
+	for _, f := range prioritizedFiles(diff) {
+		if f.IsBinary {
+			plan.Skipped = append(plan.Skipped, f.Path)
+			continue
+		}
+
+		fileCtx := buildFileContext(f)
+		fileTokens := counter.CountTokens(fileCtx)
+		if fileTokens > available {
+			fileCtx = truncateToTokens(counter, fileCtx, available-markerTokens) + truncMarker
+			fileTokens = counter.CountTokens(fileCtx)
+		}
+
+		if currentTokens+fileTokens > available {
+			if len(plan.Chunks)+1 >= maxChunks {
+				plan.Skipped = append(plan.Skipped, f.Path)
+				continue
+			}
+			flush()
+		}
+
+		current.WriteString(fileCtx)
+		currentFiles = append(currentFiles, f.Path)
+		if f.Language != "" && !slices.Contains(currentLanguages, f.Language) {
+			currentLanguages = append(currentLanguages, f.Language)
+		}
+		currentTokens += fileTokens
+	}
+	flush()
 
+	// A diff without reviewable files still gets one header-only chunk so
+	// the semantic pass can compare the PR description to the change summary.
+	if len(plan.Chunks) == 0 {
+		plan.Chunks = []ContextChunk{{Text: header}}
+	}
+
+	return plan
+}
+
+// buildContextHeader summarises the PR metadata, languages and file count.
+func buildContextHeader(diff *interfaces.Diff) string {
 	var b strings.Builder
 
-	// Header: summary of the diff.
 	if diff.PRTitle != "" {
 		fmt.Fprintf(&b, "PR: %s\n", diff.PRTitle)
 	}
@@ -61,7 +172,12 @@ func BuildContext(diff *interfaces.Diff, maxTokenBudget int) string {
 	}
 	fmt.Fprintf(&b, "Files changed: %d\n\n", len(diff.Files))
 
-	// Sort files: security-sensitive first, then by total hunk content size (descending).
+	return b.String()
+}
+
+// prioritizedFiles returns a copy of the diff's files sorted security-sensitive
+// first, then by total hunk content size (descending).
+func prioritizedFiles(diff *interfaces.Diff) []interfaces.FileDiff {
 	files := make([]interfaces.FileDiff, len(diff.Files))
 	copy(files, diff.Files)
 	sort.SliceStable(files, func(i, j int) bool {
@@ -72,31 +188,7 @@ func BuildContext(diff *interfaces.Diff, maxTokenBudget int) string {
 		}
 		return hunkSize(files[i]) > hunkSize(files[j])
 	})
-
-	// Build per-file context within budget.
-	for _, f := range files {
-		if f.IsBinary {
-			continue
-		}
-		if b.Len() >= maxChars {
-			fmt.Fprintf(&b, "\n... (remaining files truncated to fit token budget)\n")
-			break
-		}
-
-		fileCtx := buildFileContext(f)
-		if b.Len()+len(fileCtx) > maxChars {
-			// Include a truncated version of this file.
-			remaining := maxChars - b.Len()
-			if remaining > 100 {
-				b.WriteString(fileCtx[:remaining])
-				b.WriteString("\n... (file truncated)\n")
-			}
-			break
-		}
-		b.WriteString(fileCtx)
-	}
-
-	return b.String()
+	return files
 }
 
 // buildFileContext formats a single file's changes for LLM context.
@@ -149,3 +241,90 @@ func hunkSize(f interfaces.FileDiff) int {
 	}
 	return total
 }
+
+// BuildProjectContext renders the review focus areas and the contents of
+// repository files matching the given glob patterns (relative to root) for use
+// as reference material in prompts. Output stays within maxTokens as measured
+// by counter (nil uses the chars-per-token heuristic): files are added in
+// pattern order and the last one that does not fit is truncated.
+// Returns the context text and the repository-relative paths that were included.
+func BuildProjectContext(root string, patterns, focusAreas []string, counter TokenCounter, maxTokens int) (string, []string) {
+	if len(patterns) == 0 && len(focusAreas) == 0 {
+		return "", nil
+	}
+	if counter == nil {
+		counter = HeuristicCounter{}
+	}
+	const truncMarker = "\n... (truncated)\n"
+
+	var b strings.Builder
+	if len(focusAreas) > 0 {
+		b.WriteString("Review priorities for this project (weigh findings in these areas more heavily):\n")
+		for _, area := range focusAreas {
+			fmt.Fprintf(&b, "- %s\n", area)
+		}
+		b.WriteString("\n")
+	}
+
+	var included []string
+	seen := map[string]bool{}
+	for _, rel := range expandContextGlobs(root, patterns) {
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		// Reserve one token for the trailing newline added below.
+		remaining := maxTokens - counter.CountTokens(b.String()) - 1
+		if remaining < 50 {
+			slog.Debug("ai: context file budget exhausted", "skipped", rel)
+			break
+		}
+
+		data, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil || bytes.IndexByte(data, 0) != -1 {
+			continue // Unreadable or binary.
+		}
+
+		section := fmt.Sprintf("--- Reference file: %s ---\n%s\n", filepath.ToSlash(rel), data)
+		if len(included) == 0 {
+			section = "Project reference files (existing code and docs, not part of this change):\n" + section
+		}
+		if counter.CountTokens(section) > remaining {
+			section = truncateToTokens(counter, section, remaining-counter.CountTokens(truncMarker)) + truncMarker
+		}
+		b.WriteString(section)
+		included = append(included, filepath.ToSlash(rel))
+	}
+
+	if b.Len() == 0 {
+		return "", nil
+	}
+	b.WriteString("\n")
+	return b.String(), included
+}
+
+// expandContextGlobs resolves glob patterns relative to root and returns the
+// matching regular files as root-relative paths. Matches outside root are dropped.
+func expandContextGlobs(root string, patterns []string) []string {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			slog.Warn("ai: invalid context file pattern", "pattern", pattern, "error", err)
+			continue
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, m)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if info, err := os.Stat(m); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			files = append(files, rel)
+		}
+	}
+	return files
+}
@@ -0,0 +1,399 @@
+// Package eval measures AI review quality against labeled fixture diffs.
+//
+// A fixture directory holds unified diffs ("name.diff") next to their labels
+// ("name.expected.json"), which list the findings a good review reports:
+//
+//	{"findings": [
+//	  {"file": "pkg/handler/api.go", "line": 64, "category": "logic", "pass": "logic",
+//	   "title": "Encode error ignored"}
+//	]}
+//
+// A review finding matches a label when it is in the same file, within the
+// line tolerance, and has the label's category and pass (when set). Each
+// finding and label is matched at most once. Precision, recall and F1 are
+// reported overall, per pass and per category, and can be compared against a
+// stored baseline.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// DefaultLineTolerance is how many lines a finding may be from a label's line
+// and still match it.
+const DefaultLineTolerance = 3
+
+// expectedSuffix is the label file suffix that replaces a fixture's ".diff".
+const expectedSuffix = ".expected.json"
+
+// Expectation is one labeled finding.
+type Expectation struct {
+	File     string              `json:"file"`
+	Line     int                 `json:"line"`
+	Category interfaces.Category `json:"category"`
+	Pass     string              `json:"pass,omitempty"`  // empty = any pass
+	Title    string              `json:"title,omitempty"` // for readers; not matched
+}
+
+// Case is a labeled fixture diff.
+type Case struct {
+	Name     string
+	Diff     *interfaces.Diff
+	Expected []Expectation
+}
+
+// LoadCases parses every labeled diff in dir, in name order. Diffs without a
+// label file are skipped; label with an empty findings list to assert that a
+// diff is clean.
+func LoadCases(ctx context.Context, dir string, parser interfaces.DiffParser) ([]Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.diff"))
+	if err != nil {
+		return nil, fmt.Errorf("eval: listing %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	var cases []Case
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".diff")
+		labels, err := os.ReadFile(filepath.Join(dir, name+expectedSuffix))
+		if os.IsNotExist(err) {
+			slog.Debug("eval: skipping unlabeled fixture", "fixture", name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("eval: reading labels for %s: %w", name, err)
+		}
+		var parsed struct {
+			Findings []Expectation `json:"findings"`
+		}
+		if err := json.Unmarshal(labels, &parsed); err != nil {
+			return nil, fmt.Errorf("eval: parsing labels for %s: %w", name, err)
+		}
+		for i, e := range parsed.Findings {
+			if e.File == "" || e.Line <= 0 || e.Category == "" {
+				return nil, fmt.Errorf("eval: labels for %s: findings[%d]: file, line and category are required", name, i)
+			}
+		}
+
+		diff, err := parser.ParseFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("eval: parsing %s: %w", path, err)
+		}
+		cases = append(cases, Case{Name: name, Diff: diff, Expected: parsed.Findings})
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("eval: no labeled fixtures (*.diff with *%s) in %s", expectedSuffix, dir)
+	}
+	return cases, nil
+}
+
+// Metrics are the match counts and scores for a set of findings.
+type Metrics struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// score fills in Precision, Recall and F1 from the counts. Precision is 1
+// when nothing was reported and recall is 1 when nothing was expected, so a
+// clean diff reviewed as clean scores perfectly.
+func (m *Metrics) score() {
+	m.Precision, m.Recall = 1, 1
+	if n := m.TruePositives + m.FalsePositives; n > 0 {
+		m.Precision = float64(m.TruePositives) / float64(n)
+	}
+	if n := m.TruePositives + m.FalseNegatives; n > 0 {
+		m.Recall = float64(m.TruePositives) / float64(n)
+	}
+	m.F1 = 0
+	if m.Precision+m.Recall > 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+}
+
+// CaseResult is the outcome for one fixture.
+type CaseResult struct {
+	Name     string        `json:"name"`
+	Metrics  Metrics       `json:"metrics"`
+	Missed   []Expectation `json:"missed,omitempty"`
+	Spurious []Spurious    `json:"spurious,omitempty"`
+}
+
+// Spurious is a reported finding that matched no label.
+type Spurious struct {
+	File     string              `json:"file"`
+	Line     int                 `json:"line"`
+	Category interfaces.Category `json:"category"`
+	Pass     string              `json:"pass,omitempty"`
+	Title    string              `json:"title"`
+}
+
+// Report is the result of an evaluation run.
+type Report struct {
+	Overall    Metrics            `json:"overall"`
+	Passes     map[string]Metrics `json:"passes"`
+	Categories map[string]Metrics `json:"categories"`
+	Cases      []CaseResult       `json:"cases"`
+	// Regressions is filled in by Compare.
+	Regressions []Regression `json:"regressions,omitempty"`
+}
+
+// Scorer accumulates review results into a Report.
+type Scorer struct {
+	tolerance int
+	report    Report
+}
+
+// NewScorer returns a scorer matching findings within tolerance lines of a
+// label; a negative tolerance requires the exact line.
+func NewScorer(tolerance int) *Scorer {
+	return &Scorer{
+		tolerance: max(tolerance, 0),
+		report:    Report{Passes: make(map[string]Metrics), Categories: make(map[string]Metrics)},
+	}
+}
+
+// Add scores the findings a review reported for c.
+func (s *Scorer) Add(c Case, findings []interfaces.Finding) {
+	matchedFinding, matchedLabel := s.match(findings, c.Expected)
+
+	cr := CaseResult{Name: c.Name}
+	for i, f := range findings {
+		pass, _ := f.Metadata["pass"].(string)
+		if matchedFinding[i] {
+			cr.Metrics.TruePositives++
+			s.count(pass, string(f.Category), func(m *Metrics) { m.TruePositives++ })
+			continue
+		}
+		cr.Metrics.FalsePositives++
+		s.count(pass, string(f.Category), func(m *Metrics) { m.FalsePositives++ })
+		cr.Spurious = append(cr.Spurious, Spurious{
+			File: f.File, Line: f.StartLine, Category: f.Category, Pass: pass, Title: f.Title,
+		})
+	}
+	for j, e := range c.Expected {
+		if matchedLabel[j] {
+			continue
+		}
+		cr.Metrics.FalseNegatives++
+		s.count(e.Pass, string(e.Category), func(m *Metrics) { m.FalseNegatives++ })
+		cr.Missed = append(cr.Missed, e)
+	}
+
+	cr.Metrics.score()
+	s.report.Overall.TruePositives += cr.Metrics.TruePositives
+	s.report.Overall.FalsePositives += cr.Metrics.FalsePositives
+	s.report.Overall.FalseNegatives += cr.Metrics.FalseNegatives
+	s.report.Cases = append(s.report.Cases, cr)
+}
+
+// count applies inc to the pass's and the category's metrics. Findings or
+// labels without a pass only count towards their category.
+func (s *Scorer) count(pass, category string, inc func(*Metrics)) {
+	if pass != "" {
+		m := s.report.Passes[pass]
+		inc(&m)
+		s.report.Passes[pass] = m
+	}
+	m := s.report.Categories[category]
+	inc(&m)
+	s.report.Categories[category] = m
+}
+
+// match pairs findings with labels, closest first, and reports which of each
+// were matched.
+func (s *Scorer) match(findings []interfaces.Finding, expected []Expectation) (matchedFinding, matchedLabel []bool) {
+	type pair struct{ finding, label, distance int }
+	var pairs []pair
+	for i, f := range findings {
+		pass, _ := f.Metadata["pass"].(string)
+		for j, e := range expected {
+			if cleanPath(f.File) != cleanPath(e.File) || f.Category != e.Category || (e.Pass != "" && e.Pass != pass) {
+				continue
+			}
+			if d := lineDistance(f, e.Line); d <= s.tolerance {
+				pairs = append(pairs, pair{i, j, d})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].distance < pairs[b].distance })
+
+	matchedFinding = make([]bool, len(findings))
+	matchedLabel = make([]bool, len(expected))
+	for _, p := range pairs {
+		if matchedFinding[p.finding] || matchedLabel[p.label] {
+			continue
+		}
+		matchedFinding[p.finding], matchedLabel[p.label] = true, true
+	}
+	return matchedFinding, matchedLabel
+}
+
+// lineDistance is how many lines line lies outside the finding's range.
+func lineDistance(f interfaces.Finding, line int) int {
+	end := max(f.EndLine, f.StartLine)
+	switch {
+	case line < f.StartLine:
+		return f.StartLine - line
+	case line > end:
+		return line - end
+	}
+	return 0
+}
+
+// cleanPath normalises a diff path for comparison.
+func cleanPath(p string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(p)), "./")
+}
+
+// Report returns the scores for everything added so far.
+func (s *Scorer) Report() *Report {
+	r := s.report
+	r.Overall.score()
+	r.Passes = scored(r.Passes)
+	r.Categories = scored(r.Categories)
+	r.Cases = append([]CaseResult(nil), r.Cases...)
+	return &r
+}
+
+// scored returns a copy of groups with scores filled in.
+func scored(groups map[string]Metrics) map[string]Metrics {
+	out := make(map[string]Metrics, len(groups))
+	for name, m := range groups {
+		m.score()
+		out[name] = m
+	}
+	return out
+}
+
+// Scores are the precision, recall and F1 of one group.
+type Scores struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// Baseline holds the minimum acceptable scores, overall, per pass and per
+// category.
+type Baseline struct {
+	Overall    Scores            `json:"overall"`
+	Passes     map[string]Scores `json:"passes,omitempty"`
+	Categories map[string]Scores `json:"categories,omitempty"`
+}
+
+// Baseline returns the report's scores as a baseline.
+func (r *Report) Baseline() Baseline {
+	b := Baseline{
+		Overall:    scoresOf(r.Overall),
+		Passes:     make(map[string]Scores, len(r.Passes)),
+		Categories: make(map[string]Scores, len(r.Categories)),
+	}
+	for name, m := range r.Passes {
+		b.Passes[name] = scoresOf(m)
+	}
+	for name, m := range r.Categories {
+		b.Categories[name] = scoresOf(m)
+	}
+	return b
+}
+
+func scoresOf(m Metrics) Scores {
+	return Scores{Precision: m.Precision, Recall: m.Recall, F1: m.F1}
+}
+
+// LoadBaseline reads a baseline written by SaveBaseline.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("eval: reading baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("eval: parsing baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// SaveBaseline writes b to path as indented JSON.
+func SaveBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("eval: encoding baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("eval: writing baseline: %w", err)
+	}
+	return nil
+}
+
+// Regression is a score that fell below its baseline.
+type Regression struct {
+	Group    string  `json:"group"` // "overall", "pass:<name>" or "category:<name>"
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Actual   float64 `json:"actual"`
+}
+
+// scoreEpsilon absorbs rounding in stored baselines.
+const scoreEpsilon = 1e-6
+
+// Compare records in r.Regressions every score below its baseline and
+// returns them. Passes and categories missing from the report score as if
+// nothing was expected or reported in them.
+func (r *Report) Compare(b Baseline) []Regression {
+	var regressions []Regression
+	check := func(group string, want Scores, got Metrics) {
+		for _, s := range []struct {
+			metric    string
+			want, got float64
+		}{
+			{"precision", want.Precision, got.Precision},
+			{"recall", want.Recall, got.Recall},
+			{"f1", want.F1, got.F1},
+		} {
+			if s.got < s.want-scoreEpsilon {
+				regressions = append(regressions, Regression{Group: group, Metric: s.metric, Baseline: s.want, Actual: s.got})
+			}
+		}
+	}
+
+	check("overall", b.Overall, r.Overall)
+	for _, name := range sortedKeys(b.Passes) {
+		check("pass:"+name, b.Passes[name], lookup(r.Passes, name))
+	}
+	for _, name := range sortedKeys(b.Categories) {
+		check("category:"+name, b.Categories[name], lookup(r.Categories, name))
+	}
+	r.Regressions = regressions
+	return regressions
+}
+
+// lookup returns the scored metrics for name, or those of an empty group.
+func lookup(groups map[string]Metrics, name string) Metrics {
+	m, ok := groups[name]
+	if !ok {
+		m.score()
+	}
+	return m
+}
+
+func sortedKeys(m map[string]Scores) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+package eval
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// finding returns an AI review finding reported by pass.
+func finding(file string, line int, category interfaces.Category, pass string) interfaces.Finding {
+	return interfaces.Finding{
+		File: file, StartLine: line, EndLine: line, Category: category,
+		Title: "finding", Metadata: map[string]any{"pass": pass},
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestScorer_MatchesWithinTolerance(t *testing.T) {
+	c := Case{Name: "api", Expected: []Expectation{
+		{File: "pkg/api.go", Line: 65, Category: interfaces.CategoryLogic, Pass: "logic"},
+		{File: "pkg/api.go", Line: 36, Category: interfaces.CategoryConvention},
+	}}
+	s := NewScorer(DefaultLineTolerance)
+	s.Add(c, []interfaces.Finding{
+		finding("./pkg/api.go", 63, interfaces.CategoryLogic, "logic"),         // matches the first label
+		finding("pkg/api.go", 64, interfaces.CategoryLogic, "semantic"),        // label already matched, wrong pass
+		finding("pkg/api.go", 36, interfaces.CategoryLogic, "semantic"),        // wrong category
+		finding("pkg/other.go", 65, interfaces.CategoryLogic, "logic"),         // wrong file
+		finding("pkg/api.go", 30, interfaces.CategoryConvention, "convention"), // too far away
+	})
+	r := s.Report()
+
+	if r.Overall.TruePositives != 1 || r.Overall.FalsePositives != 4 || r.Overall.FalseNegatives != 1 {
+		t.Fatalf("unexpected counts: %+v", r.Overall)
+	}
+	if !near(r.Overall.Precision, 0.2) || !near(r.Overall.Recall, 0.5) || !near(r.Overall.F1, 2*0.2*0.5/0.7) {
+		t.Errorf("unexpected scores: %+v", r.Overall)
+	}
+	if m := r.Passes["logic"]; m.TruePositives != 1 || m.FalsePositives != 1 || !near(m.Precision, 0.5) {
+		t.Errorf("unexpected logic pass metrics: %+v", m)
+	}
+	if m := r.Passes["convention"]; m.FalsePositives != 1 || m.FalseNegatives != 0 {
+		t.Errorf("expected a label without a pass to count only towards its category, got %+v", m)
+	}
+	if m := r.Categories["convention"]; m.FalsePositives != 1 || m.FalseNegatives != 1 || m.Recall != 0 {
+		t.Errorf("unexpected convention category metrics: %+v", m)
+	}
+	if cr := r.Cases[0]; len(cr.Missed) != 1 || cr.Missed[0].Line != 36 || len(cr.Spurious) != 4 {
+		t.Errorf("unexpected case detail: %+v", cr)
+	}
+}
+
+func TestScorer_CleanDiffScoresPerfectly(t *testing.T) {
+	s := NewScorer(DefaultLineTolerance)
+	s.Add(Case{Name: "clean"}, nil)
+	r := s.Report()
+	if r.Overall.Precision != 1 || r.Overall.Recall != 1 || r.Overall.F1 != 1 {
+		t.Errorf("expected perfect scores for a clean diff reviewed as clean, got %+v", r.Overall)
+	}
+}
+
+func TestReport_Compare(t *testing.T) {
+	s := NewScorer(0)
+	s.Add(Case{Name: "api", Expected: []Expectation{
+		{File: "a.go", Line: 1, Category: interfaces.CategoryLogic, Pass: "logic"},
+		{File: "a.go", Line: 9, Category: interfaces.CategoryLogic, Pass: "logic"},
+	}}, []interfaces.Finding{finding("a.go", 1, interfaces.CategoryLogic, "logic")})
+	r := s.Report()
+
+	if got := r.Compare(r.Baseline()); len(got) != 0 {
+		t.Errorf("expected no regressions against its own baseline, got %+v", got)
+	}
+
+	baseline := Baseline{
+		Overall:    Scores{Precision: 1, Recall: 0.5, F1: 0.5},
+		Passes:     map[string]Scores{"logic": {Recall: 0.75}, "security": {Precision: 1, Recall: 1, F1: 1}},
+		Categories: map[string]Scores{"logic": {Recall: 0.5}},
+	}
+	got := r.Compare(baseline)
+	if len(got) != 1 || got[0].Group != "pass:logic" || got[0].Metric != "recall" || got[0].Actual != 0.5 {
+		t.Errorf("expected only the logic pass recall to regress, got %+v", got)
+	}
+	if len(r.Regressions) != 1 {
+		t.Errorf("expected the regressions recorded on the report, got %+v", r.Regressions)
+	}
+}
+
+func TestBaseline_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	want := Baseline{Overall: Scores{Precision: 0.8, Recall: 0.6, F1: 0.6857}, Passes: map[string]Scores{"logic": {Recall: 1}}}
+	if err := SaveBaseline(path, want); err != nil {
+		t.Fatalf("SaveBaseline: %v", err)
+	}
+	got, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if got.Overall != want.Overall || got.Passes["logic"] != want.Passes["logic"] {
+		t.Errorf("LoadBaseline = %+v, want %+v", got, want)
+	}
+}
+
+// fileParser returns an empty diff for any input and records the files parsed.
+type fileParser struct{ parsed []string }
+
+func (p *fileParser) Parse(_ context.Context, _ []byte) (*interfaces.Diff, error) {
+	return &interfaces.Diff{}, nil
+}
+
+func (p *fileParser) ParseFile(_ context.Context, path string) (*interfaces.Diff, error) {
+	p.parsed = append(p.parsed, filepath.Base(path))
+	return &interfaces.Diff{}, nil
+}
+
+func TestLoadCases(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"b.diff":          "",
+		"b.expected.json": `{"findings": [{"file": "a.go", "line": 3, "category": "logic"}]}`,
+		"a.diff":          "",
+		"a.expected.json": `{"findings": []}`,
+		"unlabeled.diff":  "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser := &fileParser{}
+	cases, err := LoadCases(context.Background(), dir, parser)
+	if err != nil {
+		t.Fatalf("LoadCases: %v", err)
+	}
+	if len(cases) != 2 || cases[0].Name != "a" || cases[1].Name != "b" || len(cases[1].Expected) != 1 {
+		t.Errorf("unexpected cases: %+v", cases)
+	}
+	if strings.Join(parser.parsed, ",") != "a.diff,b.diff" {
+		t.Errorf("expected unlabeled diffs to be skipped, parsed %v", parser.parsed)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.expected.json"), []byte(`{"findings": [{"file": "a.go"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCases(context.Background(), dir, parser); err == nil || !strings.Contains(err.Error(), "findings[0]") {
+		t.Errorf("expected an incomplete label to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// fixMaxTokens bounds the completion length of a fix request.
+const fixMaxTokens = 1024
+
+// needsFix reports whether a finding is severe enough to request a patch for.
+func needsFix(f interfaces.Finding) bool {
+	return f.Severity == interfaces.SeverityCritical || f.Severity == interfaces.SeverityHigh
+}
+
+// attachFixes asks the provider for a patch for every high and critical
+// finding located inside a diff hunk. Patches that apply cleanly are stored in
+// the finding's metadata ("fix_patch", "fix_start_line", "fix_end_line",
+// "fix_replacement"); everything else is dropped. Returns the number of
+// patches attached and the tokens spent requesting them.
+func (r *Reviewer) attachFixes(ctx context.Context, diff *interfaces.Diff, findings []interfaces.Finding) (int, Usage) {
+	files := make(map[string]interfaces.FileDiff, len(diff.Files))
+	for _, f := range diff.Files {
+		files[f.Path] = f
+	}
+
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	attached := 0
+	var usage Usage
+
+	for i := range findings {
+		if !needsFix(findings[i]) {
+			continue
+		}
+		file, ok := files[findings[i].File]
+		if !ok {
+			continue
+		}
+		hunk, ok := hunkForLine(file, findings[i].StartLine)
+		if !ok {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(f *interfaces.Finding) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fix, u, err := r.requestFix(ctx, file, hunk, *f)
+			mu.Lock()
+			usage = usage.Add(u)
+			mu.Unlock()
+			if err != nil {
+				slog.Debug("ai: no usable fix patch", "file", f.File, "line", f.StartLine, "error", err)
+				return
+			}
+			if f.Metadata == nil {
+				f.Metadata = make(map[string]any)
+			}
+			f.Metadata["fix_patch"] = fix.Patch
+			f.Metadata["fix_start_line"] = fix.StartLine
+			f.Metadata["fix_end_line"] = fix.EndLine
+			f.Metadata["fix_replacement"] = fix.Replacement
+
+			mu.Lock()
+			attached++
+			mu.Unlock()
+		}(&findings[i])
+	}
+	wg.Wait()
+
+	return attached, usage
+}
+
+// requestFix asks the provider for a patch resolving f and validates it
+// against the hunk it was reported in.
+func (r *Reviewer) requestFix(ctx context.Context, file interfaces.FileDiff, hunk interfaces.Hunk, f interfaces.Finding) (*FixSuggestion, Usage, error) {
+	var code strings.Builder
+	for _, l := range newSideLines(hunk) {
+		fmt.Fprintf(&code, "%d | %s\n", l.number, l.content)
+	}
+
+	response, err := r.provider.Complete(ctx,
+		prompts.FixPrompt(f.File, f.StartLine, f.Title, f.Description, f.Suggestion, prompts.FenceUntrusted(code.String())),
+		CompletionOpts{
+			MaxTokens:    fixMaxTokens,
+			SystemPrompt: prompts.HardenSystemPrompt(prompts.FixSystemPrompt()),
+		})
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	if strings.TrimSpace(response.Text) == prompts.NoFix {
+		return nil, response.Usage, fmt.Errorf("model declined to propose a fix")
+	}
+
+	// Only the hunk the finding was reported in is offered to the patch.
+	scoped := file
+	scoped.Hunks = []interfaces.Hunk{hunk}
+	fix, err := ApplyFixPatch(scoped, response.Text)
+	return fix, response.Usage, err
+}
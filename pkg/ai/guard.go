@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+const (
+	// DefaultLineTolerance is how many lines away from an added line a finding
+	// may point and still be snapped onto it.
+	DefaultLineTolerance = 5
+
+	// adjustedConfidenceFactor scales the confidence of findings whose line
+	// had to be moved, since the model was evidently unsure where the issue is.
+	adjustedConfidenceFactor = 0.75
+)
+
+// guardStats counts what validateFindings changed.
+type guardStats struct {
+	discarded int
+	adjusted  int
+}
+
+// validateFindings checks model-reported locations against the diff. Findings
+// on files that are not part of the diff are discarded. Findings on a line
+// that was not added are moved to the nearest added line within tolerance
+// (lowering their confidence), or discarded if there is none. File-level
+// findings (line 0) are kept as long as the file is in the diff.
+func validateFindings(diff *interfaces.Diff, findings []interfaces.Finding, tolerance int) ([]interfaces.Finding, guardStats) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+
+	added := make(map[string][]int, len(diff.Files))
+	for _, f := range diff.Files {
+		var lines []int
+		for _, h := range f.Hunks {
+			for _, l := range h.AddedLines {
+				lines = append(lines, l.Number)
+			}
+		}
+		added[f.Path] = lines
+	}
+
+	var stats guardStats
+	kept := make([]interfaces.Finding, 0, len(findings))
+	for _, f := range findings {
+		path, ok := matchDiffPath(added, f.File)
+		if !ok {
+			slog.Debug("ai: discarding finding on file outside the diff", "file", f.File, "title", f.Title)
+			stats.discarded++
+			continue
+		}
+		f.File = path
+
+		if f.StartLine <= 0 {
+			kept = append(kept, f)
+			continue
+		}
+
+		nearest, dist := nearestLine(added[path], f.StartLine)
+		switch {
+		case dist == 0:
+		case nearest > 0 && dist <= tolerance:
+			if f.Metadata == nil {
+				f.Metadata = make(map[string]any)
+			}
+			f.Metadata["reported_line"] = f.StartLine
+			f.EndLine += nearest - f.StartLine
+			f.StartLine = nearest
+			f.Confidence *= adjustedConfidenceFactor
+			stats.adjusted++
+		default:
+			slog.Debug("ai: discarding finding on unchanged line", "file", f.File, "line", f.StartLine, "title", f.Title)
+			stats.discarded++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, stats
+}
+
+// matchDiffPath resolves a model-reported path to a file in the diff,
+// tolerating "./" and git's "a/" / "b/" prefixes.
+func matchDiffPath(files map[string][]int, path string) (string, bool) {
+	candidates := []string{path, strings.TrimPrefix(path, "./")}
+	for _, prefix := range []string{"a/", "b/"} {
+		if strings.HasPrefix(path, prefix) {
+			candidates = append(candidates, strings.TrimPrefix(path, prefix))
+		}
+	}
+	for _, c := range candidates {
+		if _, ok := files[c]; ok && c != "" {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// nearestLine returns the line in lines closest to target and its distance.
+// Returns 0 and -1 when lines is empty.
+func nearestLine(lines []int, target int) (int, int) {
+	best, bestDist := 0, -1
+	for _, l := range lines {
+		d := l - target
+		if d < 0 {
+			d = -d
+		}
+		if bestDist == -1 || d < bestDist {
+			best, bestDist = l, d
+		}
+	}
+	return best, bestDist
+}
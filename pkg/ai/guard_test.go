@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func TestValidateFindings(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{
+		addedFile("pkg/api/handler.go", 20, 21, 22, 40),
+		{Path: "docs/renamed.md", Status: interfaces.FileRenamed},
+	}}
+
+	findings := []interfaces.Finding{
+		{Title: "exact", File: "pkg/api/handler.go", StartLine: 21, EndLine: 21, Confidence: 0.7},
+		{Title: "near", File: "pkg/api/handler.go", StartLine: 25, EndLine: 26, Confidence: 0.7},
+		{Title: "far", File: "pkg/api/handler.go", StartLine: 31, EndLine: 31, Confidence: 0.7},
+		{Title: "prefixed", File: "b/pkg/api/handler.go", StartLine: 40, EndLine: 40, Confidence: 0.7},
+		{Title: "file-level", File: "pkg/api/handler.go", Confidence: 0.7},
+		{Title: "not in diff", File: "pkg/api/router.go", StartLine: 10, Confidence: 0.7},
+		{Title: "no added lines", File: "docs/renamed.md", StartLine: 3, Confidence: 0.7},
+		{Title: "no file", StartLine: 21, Confidence: 0.7},
+	}
+
+	kept, stats := validateFindings(diff, findings, 5)
+
+	byTitle := map[string]interfaces.Finding{}
+	for _, f := range kept {
+		byTitle[f.Title] = f
+	}
+	for _, title := range []string{"exact", "near", "prefixed", "file-level"} {
+		if _, ok := byTitle[title]; !ok {
+			t.Errorf("expected %q to be kept", title)
+		}
+	}
+	if len(kept) != 4 {
+		t.Errorf("expected 4 findings kept, got %d", len(kept))
+	}
+	if stats.discarded != 4 || stats.adjusted != 1 {
+		t.Errorf("expected 4 discarded and 1 adjusted, got %+v", stats)
+	}
+
+	near := byTitle["near"]
+	if near.StartLine != 22 || near.EndLine != 23 {
+		t.Errorf("expected near finding snapped to 22-23, got %d-%d", near.StartLine, near.EndLine)
+	}
+	if near.Confidence >= 0.7 {
+		t.Errorf("expected lowered confidence on adjusted finding, got %v", near.Confidence)
+	}
+	if near.Metadata["reported_line"] != 25 {
+		t.Errorf("expected reported_line 25 in metadata, got %v", near.Metadata["reported_line"])
+	}
+	if byTitle["exact"].Confidence != 0.7 {
+		t.Error("expected exact finding confidence to be unchanged")
+	}
+	if byTitle["prefixed"].File != "pkg/api/handler.go" {
+		t.Errorf("expected prefixed path normalised, got %q", byTitle["prefixed"].File)
+	}
+}
+
+func TestValidateFindings_ZeroToleranceRequiresAddedLine(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 10)}}
+	findings := []interfaces.Finding{{Title: "off by one", File: "main.go", StartLine: 11}}
+
+	kept, stats := validateFindings(diff, findings, 0)
+	if len(kept) != 0 || stats.discarded != 1 {
+		t.Errorf("expected finding to be discarded with zero tolerance, kept %d (%+v)", len(kept), stats)
+	}
+}
+
+func TestReviewer_Review_ReportsGuardCounts(t *testing.T) {
+	provider := &mockProvider{
+		available: true,
+		responses: []string{
+			`{"findings": [
+				{"file": "main.go", "line": 12, "severity": "medium", "title": "Close to change", "description": "off by two lines"},
+				{"file": "vendor/lib.go", "line": 5, "severity": "high", "title": "Invented file", "description": "file not in the PR"}
+			]}`,
+		},
+	}
+
+	result, err := NewReviewer(provider).Review(context.Background(),
+		&interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 10)}}, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	if len(result.Findings) != 1 || result.Findings[0].StartLine != 10 {
+		t.Fatalf("expected one finding snapped to line 10, got %+v", result.Findings)
+	}
+	if result.Metadata["discarded_findings"] != 1 || result.Metadata["adjusted_findings"] != 1 {
+		t.Errorf("expected 1 discarded and 1 adjusted in metadata, got %v / %v",
+			result.Metadata["discarded_findings"], result.Metadata["adjusted_findings"])
+	}
+}
@@ -0,0 +1,367 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/analyzer"
+)
+
+// IndexVersion is the on-disk format version written by SaveIndex.
+const IndexVersion = 1
+
+// DefaultIndexFile is where the repository index is stored, relative to the
+// repository root, when no path is configured.
+const DefaultIndexFile = ".shipsafe/index.gob"
+
+// Chunking and request limits for BuildIndex.
+const (
+	indexChunkLines   = 60    // lines per chunk
+	indexChunkOverlap = 10    // lines shared with the previous chunk
+	indexBatchSize    = 64    // texts per embeddings request
+	indexMaxChunks    = 50000 // chunks per repository
+)
+
+// indexSkippedDirs are directories never indexed, besides hidden ones.
+var indexSkippedDirs = map[string]bool{"vendor": true, "node_modules": true, "testdata": true}
+
+// indexSkippedSuffixes are lock files and generated artifacts not worth indexing.
+var indexSkippedSuffixes = []string{".sum", ".lock", "-lock.json", ".min.js", ".min.css", ".map", ".svg", ".pb.go"}
+
+// Index is a local embedding index of a repository's files.
+type Index struct {
+	Version int
+	Model   string // embedding model the vectors were produced with
+	Chunks  []IndexChunk
+}
+
+// IndexChunk is an indexed span of a file.
+type IndexChunk struct {
+	Path      string // slash-separated, relative to the repository root
+	StartLine int
+	EndLine   int
+	Text      string    // file content, with secrets redacted
+	Hash      string    // of Path and Text, to reuse vectors when re-indexing
+	Vector    []float32 // unit length
+}
+
+// IndexStats reports what BuildIndex did.
+type IndexStats struct {
+	Files    int // files indexed
+	Chunks   int // chunks in the index
+	Embedded int // chunks sent to the embeddings endpoint
+	Reused   int // chunks whose vector was taken from the previous index
+}
+
+// BuildIndex splits the text files under root into overlapping line chunks
+// and embeds them. In a git work tree only tracked files are indexed, so
+// ignored and untracked files (build output, local .env files) never leave the
+// machine. Dot-files, hidden directories, vendor, node_modules, testdata, lock
+// files and binary or large files are skipped. Secrets are redacted before
+// anything is sent. Chunks unchanged since previous (when it was built with
+// the same model) keep their vectors instead of being embedded again.
+func BuildIndex(ctx context.Context, root string, embedder Embedder, model string, previous *Index) (*Index, IndexStats, error) {
+	files, err := indexFiles(ctx, root)
+	if err != nil {
+		return nil, IndexStats{}, err
+	}
+
+	var stats IndexStats
+	var chunks []IndexChunk
+	for _, rel := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := readTextFile(path)
+		if err != nil {
+			continue
+		}
+		fileChunks := chunkFile(rel, string(data))
+		if len(fileChunks) == 0 {
+			continue
+		}
+		if len(chunks)+len(fileChunks) > indexMaxChunks {
+			return nil, stats, fmt.Errorf("ai: repository has more than %d index chunks", indexMaxChunks)
+		}
+		chunks = append(chunks, fileChunks...)
+		stats.Files++
+	}
+
+	reusable := make(map[string][]float32)
+	if previous != nil && previous.Model == model {
+		for _, c := range previous.Chunks {
+			reusable[c.Hash] = c.Vector
+		}
+	}
+	var pending []int
+	for i := range chunks {
+		if v, ok := reusable[chunks[i].Hash]; ok {
+			chunks[i].Vector = v
+			stats.Reused++
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	texts := make([]string, len(pending))
+	for j, i := range pending {
+		texts[j] = embeddingText(chunks[i].Path, chunks[i].Text)
+	}
+	vectors, err := embedAll(ctx, embedder, texts)
+	if err != nil {
+		return nil, stats, err
+	}
+	for j, i := range pending {
+		chunks[i].Vector = vectors[j]
+	}
+	stats.Embedded = len(pending)
+	stats.Chunks = len(chunks)
+
+	return &Index{Version: IndexVersion, Model: model, Chunks: chunks}, stats, nil
+}
+
+// indexFiles returns the slash-separated paths, relative to root, of the files
+// to index: those git tracks when root is in a git work tree, or every file
+// under root otherwise. Paths through skipped directories and skipped files
+// are left out.
+func indexFiles(ctx context.Context, root string) ([]string, error) {
+	var files []string
+	tracked, err := gitTrackedFiles(ctx, root)
+	if err == nil {
+		for _, rel := range tracked {
+			if !skipIndexPath(rel) {
+				files = append(files, rel)
+			}
+		}
+		return files, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	slog.Warn("ai: not a git work tree, indexing every file not skipped", "root", root)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped.
+		}
+		if path == root {
+			return nil
+		}
+		if d.IsDir() {
+			if skipIndexDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !skipIndexPath(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ai: listing files: %w", err)
+	}
+	return files, nil
+}
+
+// gitTrackedFiles returns the slash-separated paths, relative to root, of the
+// files git tracks under root. It fails when root is not in a git work tree.
+func gitTrackedFiles(ctx context.Context, root string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "ls-files", "-z").Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, rel := range strings.Split(string(out), "\x00") {
+		if rel != "" {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
+
+// skipIndexPath reports whether the file at the slash-separated path rel is
+// never indexed: a dot-file, a file in a skipped directory, or a lock file or
+// generated artifact.
+func skipIndexPath(rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if skipIndexDir(dir) {
+			return true
+		}
+	}
+	name := parts[len(parts)-1]
+	return strings.HasPrefix(name, ".") || skipIndexFile(name)
+}
+
+// skipIndexDir reports whether a directory is hidden or never indexed.
+func skipIndexDir(name string) bool {
+	return strings.HasPrefix(name, ".") || indexSkippedDirs[name]
+}
+
+// skipIndexFile reports whether a file is a lock file or generated artifact.
+func skipIndexFile(name string) bool {
+	for _, suffix := range indexSkippedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkFile splits a file into overlapping line chunks with secrets redacted.
+// Blank chunks are dropped.
+func chunkFile(path, content string) []IndexChunk {
+	redacted, _ := analyzer.RedactSecrets(content)
+	lines := strings.Split(strings.TrimRight(redacted, "\n"), "\n")
+	var chunks []IndexChunk
+	for start := 0; start < len(lines); start += indexChunkLines - indexChunkOverlap {
+		end := min(start+indexChunkLines, len(lines))
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) != "" {
+			sum := sha256.Sum256([]byte(path + "\x00" + text))
+			chunks = append(chunks, IndexChunk{
+				Path:      path,
+				StartLine: start + 1,
+				EndLine:   end,
+				Text:      text,
+				Hash:      hex.EncodeToString(sum[:]),
+			})
+		}
+		if end == len(lines) {
+			break
+		}
+	}
+	return chunks
+}
+
+// embeddingText is what gets embedded for a span of a file; the path helps
+// match code to the packages and types it belongs to.
+func embeddingText(path, text string) string {
+	return path + "\n" + text
+}
+
+// embedAll embeds texts in batches and normalises the vectors to unit length.
+func embedAll(ctx context.Context, embedder Embedder, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += indexBatchSize {
+		batch := texts[start:min(start+indexBatchSize, len(texts))]
+		got, err := embedder.Embed(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("ai: embedding: %w", err)
+		}
+		if len(got) != len(batch) {
+			return nil, fmt.Errorf("ai: embedding: got %d vectors for %d texts", len(got), len(batch))
+		}
+		for _, v := range got {
+			vectors = append(vectors, normalize(v))
+		}
+	}
+	return vectors, nil
+}
+
+// normalize scales v to unit length, so cosine similarity is a dot product.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(1 / math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x * norm
+	}
+	return out
+}
+
+// IndexHit is a chunk returned by Search with its similarity to the query.
+type IndexHit struct {
+	Chunk *IndexChunk
+	Score float64
+}
+
+// Search returns the k chunks most similar to the unit-length query vector,
+// best first, leaving out chunks for which skip returns true. Chunks with
+// vectors of a different dimension are ignored.
+func (ix *Index) Search(query []float32, k int, skip func(*IndexChunk) bool) []IndexHit {
+	var hits []IndexHit
+	for i := range ix.Chunks {
+		c := &ix.Chunks[i]
+		if len(c.Vector) != len(query) || (skip != nil && skip(c)) {
+			continue
+		}
+		var dot float64
+		for j, x := range query {
+			dot += float64(x) * float64(c.Vector[j])
+		}
+		hits = append(hits, IndexHit{Chunk: c, Score: dot})
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// SaveIndex writes ix to path, creating the parent directory.
+func SaveIndex(path string, ix *Index) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("ai: creating index directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("ai: writing index: %w", err)
+	}
+	if err := gob.NewEncoder(f).Encode(ix); err != nil {
+		f.Close()      //nolint:errcheck
+		os.Remove(tmp) //nolint:errcheck
+		return fmt.Errorf("ai: encoding index: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp) //nolint:errcheck
+		return fmt.Errorf("ai: writing index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("ai: writing index: %w", err)
+	}
+	return nil
+}
+
+// LoadIndex reads an index written by SaveIndex.
+func LoadIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ai: reading index: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	var ix Index
+	if err := gob.NewDecoder(f).Decode(&ix); err != nil {
+		return nil, fmt.Errorf("ai: decoding index %s: %w", path, err)
+	}
+	if ix.Version != IndexVersion {
+		return nil, fmt.Errorf("ai: index %s has version %d, want %d; rebuild it with shipsafe index", path, ix.Version, IndexVersion)
+	}
+	return &ix, nil
+}
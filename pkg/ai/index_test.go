@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// wordEmbedder embeds texts as bags of hashed words, so texts sharing
+// identifiers are similar.
+type wordEmbedder struct {
+	mu    sync.Mutex
+	texts []string
+	err   error
+}
+
+func (e *wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.texts = append(e.texts, texts...)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, 64)
+		for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+		}) {
+			h := fnv.New32a()
+			h.Write([]byte(word)) //nolint:errcheck
+			v[h.Sum32()%64]++
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// indexRepo creates a repository with code, a lock file, a vendored package,
+// a dot-file and a hidden directory.
+func indexRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, d := range []string{"pkg/store", "vendor/lib", ".shipsafe"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(root, "pkg/store/store.go"), "package store\n\n// OpenLedger opens the ledger database.\nfunc OpenLedger(dsn string) (*Ledger, error) {\n\treturn connectLedger(dsn)\n}\n")
+	writeFile(t, filepath.Join(root, "pkg/store/config.go"), "package store\n\nconst apiKey = \"sk-abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJ\"\n")
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {\n\trenderBanner()\n}\n")
+	writeFile(t, filepath.Join(root, "go.sum"), "example.com/lib v1.0.0 h1:abc\n")
+	writeFile(t, filepath.Join(root, "vendor/lib/lib.go"), "package lib\n")
+	writeFile(t, filepath.Join(root, ".shipsafe/notes.txt"), "notes\n")
+	writeFile(t, filepath.Join(root, ".env"), "DB_PASSWORD=hunter2\n")
+	return root
+}
+
+func TestBuildIndex(t *testing.T) {
+	root := indexRepo(t)
+	embedder := &wordEmbedder{}
+
+	index, stats, err := BuildIndex(context.Background(), root, embedder, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	if stats.Files != 3 || stats.Chunks != 3 || stats.Embedded != 3 || stats.Reused != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	var paths []string
+	for _, c := range index.Chunks {
+		paths = append(paths, c.Path)
+	}
+	if got := strings.Join(paths, ","); got != "main.go,pkg/store/config.go,pkg/store/store.go" {
+		t.Errorf("expected lock files, vendor, dot-files and hidden directories skipped, indexed %s", got)
+	}
+	for _, text := range embedder.texts {
+		if strings.Contains(text, "sk-abcdefghijklmnopqrstuvwxyz") {
+			t.Errorf("expected secrets redacted before embedding, sent %q", text)
+		}
+	}
+
+	// Re-indexing after one file changed embeds only that file.
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {\n\trenderBanner()\n\tOpenLedger(\"\")\n}\n")
+	embedder.texts = nil
+	_, stats, err = BuildIndex(context.Background(), root, embedder, "words", index)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	if stats.Embedded != 1 || stats.Reused != 2 || !strings.HasPrefix(embedder.texts[0], "main.go\n") {
+		t.Errorf("expected only main.go re-embedded, got %+v, sent %q", stats, embedder.texts)
+	}
+
+	// Vectors from another model are never reused.
+	if _, stats, _ = BuildIndex(context.Background(), root, embedder, "other", index); stats.Reused != 0 {
+		t.Errorf("expected no reuse across models, got %+v", stats)
+	}
+}
+
+func TestBuildIndex_OnlyTrackedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := indexRepo(t)
+	writeFile(t, filepath.Join(root, ".gitignore"), "build/\n")
+	if err := os.MkdirAll(filepath.Join(root, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "build/bundle.js"), "const dbPassword = \"hunter2\";\n")
+	writeFile(t, filepath.Join(root, "scratch.go"), "package main\n")
+	for _, args := range [][]string{{"init", "-q"}, {"add", "main.go", "pkg", ".env", ".gitignore"}} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	index, _, err := BuildIndex(context.Background(), root, &wordEmbedder{}, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	var paths []string
+	for _, c := range index.Chunks {
+		paths = append(paths, c.Path)
+	}
+	if got := strings.Join(paths, ","); got != "main.go,pkg/store/config.go,pkg/store/store.go" {
+		t.Errorf("expected only tracked files that are not dot-files indexed, indexed %s", got)
+	}
+}
+
+func TestChunkFile_Overlaps(t *testing.T) {
+	var b strings.Builder
+	for i := 1; i <= 130; i++ {
+		b.WriteString("line\n")
+	}
+	chunks := chunkFile("big.go", b.String())
+	var spans []string
+	for _, c := range chunks {
+		spans = append(spans, fmt.Sprintf("%s:%d-%d", c.Path, c.StartLine, c.EndLine))
+	}
+	if got := strings.Join(spans, " "); got != "big.go:1-60 big.go:51-110 big.go:101-130" {
+		t.Errorf("unexpected chunks %s", got)
+	}
+}
+
+func TestIndex_SaveLoadAndSearch(t *testing.T) {
+	root := indexRepo(t)
+	index, _, err := BuildIndex(context.Background(), root, &wordEmbedder{}, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "nested", "index.gob")
+	if err := SaveIndex(path, index); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+	loaded, err := LoadIndex(path)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if loaded.Model != "words" || len(loaded.Chunks) != len(index.Chunks) {
+		t.Fatalf("unexpected index %+v", loaded)
+	}
+
+	query, err := embedAll(context.Background(), &wordEmbedder{}, []string{"OpenLedger ledger dsn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := loaded.Search(query[0], 1, nil)
+	if len(hits) != 1 || hits[0].Chunk.Path != "pkg/store/store.go" {
+		t.Errorf("expected the ledger code to be most similar, got %+v", hits)
+	}
+	skipStore := func(c *IndexChunk) bool { return c.Path == "pkg/store/store.go" }
+	if hits := loaded.Search(query[0], 5, skipStore); len(hits) != 2 {
+		t.Errorf("expected skipped chunks left out, got %d hits", len(hits))
+	}
+}
+
+func TestBuildIndex_EmbeddingError(t *testing.T) {
+	down := errors.New("connection refused")
+	if _, _, err := BuildIndex(context.Background(), indexRepo(t), &wordEmbedder{err: down}, "words", nil); !errors.Is(err, down) {
+		t.Errorf("expected the embedding error, got %v", err)
+	}
+}
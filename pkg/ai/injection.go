@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// injectionRule is a phrase pattern typical of text that tries to steer an
+// automated reviewer rather than describe the code.
+type injectionRule struct {
+	pattern *regexp.Regexp
+	what    string // completes "Added line ..."
+}
+
+// injectionRules are checked against every added line and the PR title and
+// description, case-insensitively.
+var injectionRules = []injectionRule{
+	{
+		regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass)\s+(all\s+|any\s+|the\s+|your\s+)*(previous|prior|above|earlier|preceding|system|original)\s+(instructions|prompts?|rules|directions|guidelines|context)`),
+		"tells the reader to ignore its previous instructions",
+	},
+	{
+		regexp.MustCompile(`(?i)\b(return|report|output|respond\s+with|reply\s+with|produce)\s+(no|zero|an\s+empty\s+(list\s+of\s+)?|0)\s*(findings|issues|vulnerabilities|problems|warnings)\b`),
+		"asks for the review to report no findings",
+	},
+	{
+		regexp.MustCompile(`(?i)\b(note|message|instructions?)\s+(to|for)\s+(the\s+)?(ai|llm|gpt|claude|assistant|language\s+model|code\s+reviewers?|automated\s+reviewers?|review\s+bots?)\b`),
+		"addresses an AI or automated reviewer directly",
+	},
+	{
+		regexp.MustCompile(`(?i)\b(ai|llm|automated|security)\s+(reviewers?|scanners?|assistants?)\s*[:,]\s*(please\s+)?(ignore|skip|approve|do\s+not|don't)\b`),
+		"addresses an AI or automated reviewer directly",
+	},
+	{
+		regexp.MustCompile(`(?i)\b(mark|rate|treat|classify|approve)\s+(this|the)\s+(pr|pull\s+request|change|diff|code|file)\s+(as\s+)?(safe|secure|approved|green|clean|trusted)\b`),
+		"asks for the change to be approved as safe",
+	},
+	{
+		regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|in)\b|\bnew\s+(system\s+)?instructions\s*:`),
+		"tries to replace the reader's instructions or role",
+	},
+	{
+		regexp.MustCompile(`(?i)<\|im_(start|end)\|>|<\|(system|assistant|user)\|>|\[/?INST\]|<</?SYS>>|</?system>`),
+		"contains chat-format control tokens",
+	},
+	{
+		regexp.MustCompile(`(?i)\b(BEGIN|END)\s+UNTRUSTED\b`),
+		"imitates the untrusted-content markers used in review prompts",
+	},
+}
+
+// DetectPromptInjection scans the added lines of a diff, and its PR title and
+// description, for text aimed at manipulating an AI reviewer. Each matching
+// line yields one security finding. Findings from the PR description have no
+// file. Test files are reported at medium severity, since tests for review
+// tooling legitimately contain such phrases.
+func DetectPromptInjection(diff *interfaces.Diff) []interfaces.Finding {
+	var findings []interfaces.Finding
+	add := func(file string, line int, text string, sev interfaces.Severity) {
+		for _, rule := range injectionRules {
+			if !rule.pattern.MatchString(text) {
+				continue
+			}
+			where := "Added line"
+			if file == "" {
+				where = "The pull request description"
+			}
+			findings = append(findings, interfaces.Finding{
+				ID:        fmt.Sprintf("AI-INJECTION-%d", len(findings)+1),
+				Category:  interfaces.CategorySecurity,
+				Severity:  sev,
+				File:      file,
+				StartLine: line,
+				EndLine:   line,
+				Title:     "Possible prompt injection aimed at reviewers",
+				Description: fmt.Sprintf("%s %s: %q. Text like this can manipulate AI code review into missing issues.",
+					where, rule.what, truncateStr(strings.TrimSpace(text), 120)),
+				Suggestion: "Remove the text, or confirm it is intentional (e.g. test data) and review the change manually.",
+				Source:     "prompt-injection",
+				Confidence: 0.8,
+			})
+			return // One finding per line.
+		}
+	}
+
+	for _, text := range []string{diff.PRTitle, diff.PRBody} {
+		for _, line := range strings.Split(text, "\n") {
+			add("", 0, line, interfaces.SeverityHigh)
+		}
+	}
+	for _, f := range diff.Files {
+		if f.IsBinary || f.Status == interfaces.FileDeleted {
+			continue
+		}
+		sev := interfaces.SeverityHigh
+		if isTestPath(f.Path) {
+			sev = interfaces.SeverityMedium
+		}
+		for _, h := range f.Hunks {
+			for _, l := range h.AddedLines {
+				add(f.Path, l.Number, l.Content, sev)
+			}
+		}
+	}
+	return findings
+}
+
+// isTestPath reports whether path looks like a test file or test fixture.
+func isTestPath(path string) bool {
+	p := "/" + strings.ToLower(path)
+	for _, marker := range []string{"_test.", ".test.", ".spec.", "/test_", "/testdata/", "/fixtures/", "/tests/"} {
+		if strings.Contains(p, marker) {
+			return true
+		}
+	}
+	return false
+}
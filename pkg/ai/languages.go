@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+)
+
+// LanguagePack is language-specific review guidance, keyed by pass name
+// ("logic", "convention", or a custom pass), appended to that pass's system
+// prompt when a file of the language is in the reviewed chunk.
+type LanguagePack map[string]string
+
+// WithLanguagePacks overrides the built-in language packs, keyed by language
+// as in FileDiff.Language ("go", "python", ...). A language given here
+// replaces its built-in pack entirely, so an empty pack turns a built-in one
+// off; other languages keep theirs. A pack for "typescript" or "javascript"
+// also covers the react variants unless they have their own.
+func WithLanguagePacks(packs map[string]LanguagePack) Option {
+	return func(r *Reviewer) {
+		if r.languagePacks == nil {
+			r.languagePacks = make(map[string]LanguagePack)
+		}
+		for lang, pack := range packs {
+			r.languagePacks[strings.ToLower(strings.TrimSpace(lang))] = pack
+		}
+	}
+}
+
+// languageSection returns the language-specific additions to the pass's
+// system prompt for a chunk containing files in languages. A configured pack
+// for the language itself wins over one for the language it shares a pack
+// with ("typescript" for "typescriptreact"), which wins over the built-in one.
+func (r *Reviewer) languageSection(pass string, languages []string) string {
+	guidance := make([]string, len(languages))
+	for i, lang := range languages {
+		if pack, ok := r.languagePacks[lang]; ok {
+			guidance[i] = pack[pass]
+		} else if pack, ok := r.languagePacks[prompts.PackLanguage(lang)]; ok {
+			guidance[i] = pack[pass]
+		} else {
+			guidance[i] = prompts.LanguageGuidance(lang, pass)
+		}
+	}
+	return prompts.LanguageSection(languages, guidance)
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// systemPromptsByPass reviews diff and returns the system prompt each
+// built-in pass was sent.
+func systemPromptsByPass(t *testing.T, diff *interfaces.Diff, opts ...Option) map[string]string {
+	t.Helper()
+	var mu sync.Mutex
+	got := make(map[string]string)
+	provider := &funcProvider{complete: func(_ string, opts CompletionOpts) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(opts.SystemPrompt, "semantic analysis"):
+			got["semantic"] = opts.SystemPrompt
+		case strings.Contains(opts.SystemPrompt, "logic error detection"):
+			got["logic"] = opts.SystemPrompt
+		case strings.Contains(opts.SystemPrompt, "coding convention"):
+			got["convention"] = opts.SystemPrompt
+		}
+		return `{"findings": []}`
+	}}
+	if _, err := NewReviewer(provider, opts...).Review(context.Background(), diff, nil); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	return got
+}
+
+func TestReviewer_Review_LanguagePacksFollowChunkLanguages(t *testing.T) {
+	py := addedFile("app/views.py", 3)
+	py.Language = "python"
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5), py}}
+
+	got := systemPromptsByPass(t, diff)
+	if logic := got["logic"]; !strings.Contains(logic, "goroutine leaks") || !strings.Contains(logic, "Mutable default arguments") {
+		t.Errorf("expected the Go and Python logic checks, got %q", logic)
+	}
+	if !strings.Contains(got["convention"], "In go files") || strings.Contains(got["convention"], "goroutine leaks") {
+		t.Errorf("expected the Go convention checks only, got %q", got["convention"])
+	}
+	if strings.Contains(got["semantic"], "Language-specific checks") {
+		t.Errorf("expected the semantic pass to stay language-agnostic, got %q", got["semantic"])
+	}
+
+	goOnly := systemPromptsByPass(t, &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5)}})
+	if strings.Contains(goOnly["logic"], "Mutable default arguments") {
+		t.Error("expected no Python checks for a Go-only change")
+	}
+}
+
+func TestReviewer_Review_LanguagePackOverrides(t *testing.T) {
+	py := addedFile("app/views.py", 3)
+	py.Language = "python"
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 5), py}}
+
+	got := systemPromptsByPass(t, diff, WithLanguagePacks(map[string]LanguagePack{
+		"Go":     {"logic": "- errgroup contexts ignored."},
+		"python": {},
+	}))
+	logic := got["logic"]
+	if !strings.Contains(logic, "errgroup contexts ignored") || strings.Contains(logic, "goroutine leaks") {
+		t.Errorf("expected the configured Go pack to replace the built-in one, got %q", logic)
+	}
+	if strings.Contains(logic, "Mutable default arguments") || strings.Contains(logic, "In python files") {
+		t.Errorf("expected the empty Python pack to turn it off, got %q", logic)
+	}
+	if strings.Contains(got["convention"], "Language-specific checks") {
+		t.Errorf("expected no convention checks once the Go pack has none, got %q", got["convention"])
+	}
+}
+
+func TestReviewer_Review_LanguagePackOverridesApplyToAliases(t *testing.T) {
+	tsx := addedFile("web/App.tsx", 3)
+	tsx.Language = "typescriptreact"
+	jsx := addedFile("web/Nav.jsx", 3)
+	jsx.Language = "javascriptreact"
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{tsx, jsx}}
+
+	got := systemPromptsByPass(t, diff, WithLanguagePacks(map[string]LanguagePack{
+		"typescript":      {"logic": "- Non-null assertions on API responses."},
+		"javascript":      {"logic": "- Stale closures over state."},
+		"javascriptreact": {"logic": "- Effects without cleanup."},
+	}))
+	logic := got["logic"]
+	if !strings.Contains(logic, "In typescriptreact files, also check for:\n- Non-null assertions on API responses.") {
+		t.Errorf("expected the typescript override to apply to .tsx files, got %q", logic)
+	}
+	if !strings.Contains(logic, "Effects without cleanup") || strings.Contains(logic, "Stale closures") {
+		t.Errorf("expected an override for the language itself to win over its alias, got %q", logic)
+	}
+}
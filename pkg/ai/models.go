@@ -0,0 +1,106 @@
+package ai
+
+import "strings"
+
+// promptOverheadTokens is reserved for the system prompt and the user prompt
+// template wrapped around the diff context.
+const promptOverheadTokens = 1024
+
+// minContextBudget is the smallest diff context budget ContextBudget returns.
+const minContextBudget = 512
+
+// modelContextWindows maps model name prefixes to context window sizes in
+// tokens. The longest matching prefix wins, so "gpt-4o" takes precedence
+// over "gpt-4" and "llama3.1" over "llama3".
+var modelContextWindows = map[string]int{
+	// OpenAI
+	"gpt-4.1":       1047576,
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4-32k":     32768,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o1":            200000,
+	"o3":            200000,
+	"o4-mini":       200000,
+
+	// Anthropic
+	"claude": 200000,
+
+	// Common local models (Ollama / llama.cpp naming)
+	"llama3.3":       131072,
+	"llama3.2":       131072,
+	"llama3.1":       131072,
+	"llama3":         8192,
+	"llama2":         4096,
+	"codellama":      16384,
+	"mistral":        32768,
+	"mixtral":        32768,
+	"qwen2.5-coder":  32768,
+	"deepseek-coder": 16384,
+	"gemma2":         8192,
+	"phi3":           4096,
+}
+
+// ContextWindow returns the context window size in tokens for model, or 0 if
+// the model is unknown. Matching is case-insensitive on the name prefix, and
+// a routing prefix such as "openai/" or "anthropic/" is ignored.
+func ContextWindow(model string) int {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	best, window := 0, 0
+	for prefix, size := range modelContextWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > best {
+			best, window = len(prefix), size
+		}
+	}
+	return window
+}
+
+// ContextBudget returns the token budget for diff context given a model's
+// context window and the largest completion any pass may request. The prompt
+// overhead is reserved as well. A window of 0 (unknown model) yields
+// DefaultMaxTokenBudget.
+func ContextBudget(window, maxOutputTokens int) int {
+	if window <= 0 {
+		return DefaultMaxTokenBudget
+	}
+	budget := window - maxOutputTokens - promptOverheadTokens
+	if budget < minContextBudget {
+		budget = minContextBudget
+	}
+	return budget
+}
+
+// Price is what a model charges, in USD per million tokens.
+type Price struct {
+	Input  float64 // prompt tokens
+	Output float64 // completion tokens
+}
+
+// Cost returns the USD cost of u at this price.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// LookupPrice finds the price for model in a user-supplied table. An exact
+// (case-insensitive) match wins, otherwise the longest key that prefixes the
+// model name, mirroring ContextWindow.
+func LookupPrice(prices map[string]Price, model string) (Price, bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	best := -1
+	var price Price
+	for key, p := range prices {
+		k := strings.ToLower(key)
+		if k == name {
+			return p, true
+		}
+		if strings.HasPrefix(name, k) && len(k) > best {
+			best, price = len(k), p
+		}
+	}
+	return price, best >= 0
+}
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-4o-mini", 128000},
+		{"gpt-4", 8192},
+		{"GPT-4-Turbo-2024-04-09", 128000},
+		{"claude-3-5-haiku-latest", 200000},
+		{"llama3.1:8b", 131072},
+		{"llama3:8b", 8192},
+		{"openrouter/anthropic/claude-3.5-sonnet", 200000},
+		{"my-finetune", 0},
+	}
+	for _, tt := range tests {
+		if got := ContextWindow(tt.model); got != tt.want {
+			t.Errorf("ContextWindow(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestContextBudget(t *testing.T) {
+	if got := ContextBudget(0, 2048); got != DefaultMaxTokenBudget {
+		t.Errorf("unknown window: expected default budget, got %d", got)
+	}
+	if got := ContextBudget(8192, 2048); got != 8192-2048-promptOverheadTokens {
+		t.Errorf("unexpected budget for 8k window: %d", got)
+	}
+	if got := ContextBudget(2048, 2048); got != minContextBudget {
+		t.Errorf("expected budget floor %d, got %d", minContextBudget, got)
+	}
+}
+
+func TestLookupPrice(t *testing.T) {
+	prices := map[string]Price{
+		"gpt-4o":      {Input: 2.50, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.60},
+	}
+
+	p, ok := LookupPrice(prices, "gpt-4o-mini-2024-07-18")
+	if !ok || p.Input != 0.15 {
+		t.Errorf("expected longest prefix gpt-4o-mini, got %+v (ok=%v)", p, ok)
+	}
+	if _, ok := LookupPrice(prices, "llama3:8b"); ok {
+		t.Error("expected no price for an unlisted model")
+	}
+
+	cost := p.Cost(Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	if math.Abs(cost-0.45) > 1e-9 {
+		t.Errorf("expected cost 0.45, got %v", cost)
+	}
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"log/slog"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// Defaults applied to review passes that do not set their own limits.
+const (
+	DefaultPassMaxTokens   = 2048
+	DefaultPassTemperature = 0.1
+)
+
+// PassConfig defines a user-supplied review pass. Custom passes share the
+// built-in JSON response contract, so their output is parsed like any other pass.
+type PassConfig struct {
+	Name         string              // Unique pass name, e.g. "gdpr"
+	Category     interfaces.Category // Category assigned to the pass's findings
+	SystemPrompt string              // Review instructions; the JSON contract is appended
+	Temperature  *float64            // nil = DefaultPassTemperature
+	MaxTokens    int                 // 0 = DefaultPassMaxTokens
+}
+
+// passDefinition is a review pass template, instantiated once per context chunk.
+type passDefinition struct {
+	name         string
+	category     interfaces.Category
+	systemPrompt string
+	userPrompt   func(diffContext string) string
+	maxTokens    int
+	temperature  *float64
+}
+
+// builtinPasses returns the semantic, logic and convention pass templates.
+func builtinPasses() []passDefinition {
+	return []passDefinition{
+		{
+			name:         "semantic",
+			category:     interfaces.CategoryLogic,
+			systemPrompt: prompts.SemanticSystemPrompt(),
+			userPrompt:   prompts.SemanticPrompt,
+		},
+		{
+			name:         "logic",
+			category:     interfaces.CategoryLogic,
+			systemPrompt: prompts.LogicSystemPrompt(),
+			userPrompt:   prompts.LogicPrompt,
+		},
+		{
+			name:         "convention",
+			category:     interfaces.CategoryConvention,
+			systemPrompt: prompts.ConventionSystemPrompt(),
+			userPrompt:   prompts.ConventionPrompt,
+		},
+	}
+}
+
+// passDefinitions returns the enabled built-in passes followed by the custom
+// passes, with default limits filled in.
+func (r *Reviewer) passDefinitions() []passDefinition {
+	var defs []passDefinition
+	for _, def := range builtinPasses() {
+		if r.disabledPasses[def.name] {
+			slog.Debug("AI review pass disabled", "pass", def.name)
+			continue
+		}
+		defs = append(defs, def)
+	}
+
+	for _, pc := range r.customPasses {
+		name := pc.Name
+		category := pc.Category
+		if category == "" {
+			category = interfaces.CategoryLogic
+		}
+		defs = append(defs, passDefinition{
+			name:         name,
+			category:     category,
+			systemPrompt: prompts.CustomSystemPrompt(pc.SystemPrompt),
+			userPrompt: func(diffContext string) string {
+				return prompts.CustomPrompt(name, diffContext)
+			},
+			maxTokens:   pc.MaxTokens,
+			temperature: pc.Temperature,
+		})
+	}
+
+	for i := range defs {
+		if defs[i].maxTokens <= 0 {
+			defs[i].maxTokens = DefaultPassMaxTokens
+		}
+		if defs[i].temperature == nil {
+			t := DefaultPassTemperature
+			defs[i].temperature = &t
+		}
+	}
+	return defs
+}
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// patchHeaderRegex matches a unified diff hunk header.
+var patchHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// hunkLine is one line of the post-change file inside a diff hunk.
+type hunkLine struct {
+	number  int
+	content string
+}
+
+// patchHunk is a single hunk of a model-proposed patch. lines keep their
+// ' ', '+' or '-' prefix.
+type patchHunk struct {
+	oldStart int
+	lines    []string
+}
+
+// FixSuggestion is a validated patch expressed as a replacement of a line
+// range in the post-change file.
+type FixSuggestion struct {
+	StartLine   int    // First replaced line (new-file numbering)
+	EndLine     int    // Last replaced line
+	Replacement string // Lines that replace StartLine..EndLine
+	Patch       string // Normalised unified diff hunk
+}
+
+// newSideLines returns the post-change lines covered by a hunk with their
+// new-file line numbers.
+func newSideLines(h interfaces.Hunk) []hunkLine {
+	if h.Content == "" {
+		return nil
+	}
+	var lines []hunkLine
+	n := h.NewStart
+	for _, l := range strings.Split(h.Content, "\n") {
+		if l == "" {
+			lines = append(lines, hunkLine{number: n})
+			n++
+			continue
+		}
+		switch l[0] {
+		case '-', '\\':
+			continue
+		case '+', ' ':
+			lines = append(lines, hunkLine{number: n, content: l[1:]})
+		default:
+			lines = append(lines, hunkLine{number: n, content: l})
+		}
+		n++
+	}
+	return lines
+}
+
+// hunkForLine returns the hunk of f whose post-change range contains line.
+func hunkForLine(f interfaces.FileDiff, line int) (interfaces.Hunk, bool) {
+	for _, h := range f.Hunks {
+		if line >= h.NewStart && line < h.NewStart+h.NewLines {
+			return h, true
+		}
+	}
+	return interfaces.Hunk{}, false
+}
+
+// parsePatch extracts unified diff hunks from a model response, ignoring
+// markdown fences and file headers.
+func parsePatch(response string) ([]patchHunk, error) {
+	text := strings.TrimRight(stripCodeFences(response), "\n")
+
+	var hunks []patchHunk
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "@@") {
+			m := patchHeaderRegex.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, patchHunk{oldStart: start})
+			continue
+		}
+		if len(hunks) == 0 {
+			continue // File headers or preamble.
+		}
+		cur := &hunks[len(hunks)-1]
+		switch {
+		case line == "":
+			cur.lines = append(cur.lines, " ")
+		case line[0] == ' ' || line[0] == '+' || line[0] == '-':
+			cur.lines = append(cur.lines, line)
+		case line[0] == '\\':
+			// "\ No newline at end of file"
+		default:
+			return nil, fmt.Errorf("unexpected patch line %q", truncateStr(line, 80))
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found")
+	}
+	return hunks, nil
+}
+
+// ApplyFixPatch checks that a model-proposed patch applies cleanly to the
+// post-change lines of the file's diff hunks and returns it as a line-range
+// replacement. The patch must consist of one hunk whose context and removed
+// lines match consecutive lines of a single hunk exactly (trailing whitespace
+// aside). Like git apply, the hunk may be found at an offset from its stated
+// start line; the closest match wins.
+func ApplyFixPatch(f interfaces.FileDiff, patch string) (*FixSuggestion, error) {
+	hunks, err := parsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	if len(hunks) != 1 {
+		return nil, fmt.Errorf("expected a single hunk, got %d", len(hunks))
+	}
+	ph := hunks[0]
+
+	var before, after []string
+	changed := false
+	for _, l := range ph.lines {
+		switch l[0] {
+		case ' ':
+			before = append(before, l[1:])
+			after = append(after, l[1:])
+		case '-':
+			before = append(before, l[1:])
+			changed = true
+		case '+':
+			after = append(after, l[1:])
+			changed = true
+		}
+	}
+	if !changed {
+		return nil, fmt.Errorf("patch makes no changes")
+	}
+	if len(before) == 0 {
+		return nil, fmt.Errorf("patch has no context or removed lines to anchor it")
+	}
+
+	var best []hunkLine
+	bestDist := -1
+	for _, h := range f.Hunks {
+		lines := newSideLines(h)
+		for p := 0; p+len(before) <= len(lines); p++ {
+			if !linesMatch(lines[p:p+len(before)], before) {
+				continue
+			}
+			dist := lines[p].number - ph.oldStart
+			if dist < 0 {
+				dist = -dist
+			}
+			if bestDist == -1 || dist < bestDist {
+				best, bestDist = lines[p:p+len(before)], dist
+			}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("patch does not apply to %s", f.Path)
+	}
+
+	start := best[0].number
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start, len(before), start, len(after))
+	for _, l := range ph.lines {
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+
+	return &FixSuggestion{
+		StartLine:   start,
+		EndLine:     best[len(best)-1].number,
+		Replacement: strings.Join(after, "\n"),
+		Patch:       b.String(),
+	}, nil
+}
+
+// linesMatch compares file lines to patch lines, ignoring trailing whitespace.
+func linesMatch(file []hunkLine, patch []string) bool {
+	for i := range patch {
+		if strings.TrimRight(file[i].content, " \t") != strings.TrimRight(patch[i], " \t") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// fixDiff returns a diff adding a query built by string concatenation at
+// lines 10-14 of db/user.go.
+func fixDiff() *interfaces.Diff {
+	content := strings.Join([]string{
+		" func FindUser(db *sql.DB, name string) (*User, error) {",
+		"-\treturn nil, nil",
+		"+\tq := \"SELECT * FROM users WHERE name = '\" + name + \"'\"",
+		"+\trow := db.QueryRow(q)",
+		"+\treturn scanUser(row)",
+		" }",
+	}, "\n")
+	return &interfaces.Diff{Files: []interfaces.FileDiff{{
+		Path:   "db/user.go",
+		Status: interfaces.FileModified,
+		Hunks: []interfaces.Hunk{{
+			OldStart: 10, OldLines: 3, NewStart: 10, NewLines: 5,
+			Content: content,
+			AddedLines: []interfaces.Line{
+				{Number: 11, Content: "\tq := \"SELECT * FROM users WHERE name = '\" + name + \"'\""},
+				{Number: 12, Content: "\trow := db.QueryRow(q)"},
+				{Number: 13, Content: "\treturn scanUser(row)"},
+			},
+		}},
+	}}}
+}
+
+const sqlFixPatch = "```diff\n" +
+	"--- a/db/user.go\n" +
+	"+++ b/db/user.go\n" +
+	"@@ -11,2 +11,1 @@\n" +
+	"-\tq := \"SELECT * FROM users WHERE name = '\" + name + \"'\"\n" +
+	"-\trow := db.QueryRow(q)\n" +
+	"+\trow := db.QueryRow(\"SELECT * FROM users WHERE name = ?\", name)\n" +
+	"```"
+
+func TestApplyFixPatch_Clean(t *testing.T) {
+	fix, err := ApplyFixPatch(fixDiff().Files[0], sqlFixPatch)
+	if err != nil {
+		t.Fatalf("ApplyFixPatch: %v", err)
+	}
+	if fix.StartLine != 11 || fix.EndLine != 12 {
+		t.Errorf("expected lines 11-12, got %d-%d", fix.StartLine, fix.EndLine)
+	}
+	if fix.Replacement != "\trow := db.QueryRow(\"SELECT * FROM users WHERE name = ?\", name)" {
+		t.Errorf("unexpected replacement %q", fix.Replacement)
+	}
+	if !strings.HasPrefix(fix.Patch, "@@ -11,2 +11,1 @@\n") {
+		t.Errorf("expected normalised hunk header, got %q", fix.Patch)
+	}
+}
+
+func TestApplyFixPatch_ToleratesLineOffset(t *testing.T) {
+	patch := strings.Replace(sqlFixPatch, "@@ -11,2 +11,1 @@", "@@ -3,2 +3,1 @@", 1)
+	fix, err := ApplyFixPatch(fixDiff().Files[0], patch)
+	if err != nil {
+		t.Fatalf("ApplyFixPatch: %v", err)
+	}
+	if fix.StartLine != 11 {
+		t.Errorf("expected the hunk to be located at line 11, got %d", fix.StartLine)
+	}
+}
+
+func TestApplyFixPatch_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"context mismatch", "@@ -11,1 +11,1 @@\n-\tq := buildQuery(name)\n+\tq := safeQuery(name)\n"},
+		{"removed line from old side", "@@ -10,1 +10,1 @@\n-\treturn nil, nil\n+\treturn nil, errNotFound\n"},
+		{"no changes", "@@ -10,1 +10,1 @@\n func FindUser(db *sql.DB, name string) (*User, error) {\n"},
+		{"no anchor", "@@ -0,0 +1,1 @@\n+// comment\n"},
+		{"no hunk", "Use a parameterised query instead."},
+		{"two hunks", "@@ -11,1 +11,1 @@\n-\trow := db.QueryRow(q)\n+\trow := db.QueryRow(q, name)\n@@ -14,1 +14,1 @@\n }\n+// end\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyFixPatch(fixDiff().Files[0], tt.patch); err == nil {
+				t.Error("expected patch to be rejected")
+			}
+		})
+	}
+}
+
+func TestReviewer_FixPatches(t *testing.T) {
+	provider := &funcProvider{complete: func(prompt string, opts CompletionOpts) string {
+		if strings.HasPrefix(opts.SystemPrompt, prompts.FixSystemPrompt()) {
+			if !strings.Contains(prompt, "11 | \tq := ") {
+				t.Errorf("expected numbered hunk lines in fix prompt, got:\n%s", prompt)
+			}
+			if !strings.HasPrefix(prompt, "Finding:\nBEGIN UNTRUSTED ") {
+				t.Errorf("expected the finding fenced in fix prompt, got:\n%s", prompt)
+			}
+			if strings.Contains(prompt, "Hardcoded") {
+				return "@@ -12,1 +12,1 @@\n-\tnot in the file\n+\tstill not\n"
+			}
+			return sqlFixPatch
+		}
+		if strings.Contains(opts.SystemPrompt, "logic") {
+			return `{"findings": [
+				{"file": "db/user.go", "line": 11, "severity": "critical", "title": "SQL injection", "description": "Query built from user input."},
+				{"file": "db/user.go", "line": 13, "severity": "high", "title": "Hardcoded table", "description": "Table name is fixed."},
+				{"file": "db/user.go", "line": 12, "severity": "low", "title": "Naming", "description": "Short variable name."}
+			]}`
+		}
+		return `{"findings": []}`
+	}}
+
+	reviewer := NewReviewer(provider, WithFixPatches(true))
+	result, err := reviewer.Review(context.Background(), fixDiff(), nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	byTitle := map[string]interfaces.Finding{}
+	for _, f := range result.Findings {
+		byTitle[f.Title] = f
+	}
+	if got := byTitle["SQL injection"].Metadata["fix_start_line"]; got != 11 {
+		t.Errorf("expected fix for SQL injection at line 11, got %v", got)
+	}
+	if _, ok := byTitle["Hardcoded table"].Metadata["fix_patch"]; ok {
+		t.Error("expected non-applying patch to be dropped")
+	}
+	if _, ok := byTitle["Naming"].Metadata["fix_patch"]; ok {
+		t.Error("expected no fix to be requested for low severity findings")
+	}
+	if result.Metadata["fix_patches"] != 1 {
+		t.Errorf("expected fix_patches=1, got %v", result.Metadata["fix_patches"])
+	}
+}
@@ -0,0 +1,42 @@
+package prompts
+
+import "fmt"
+
+// responseContract is appended to user-defined system prompts so custom passes
+// answer in the same JSON shape as the built-in passes.
+const responseContract = `You MUST respond with valid JSON only. No markdown, no commentary outside the JSON.
+
+Response format:
+{
+  "findings": [
+    {
+      "file": "path/to/file.go",
+      "line": 42,
+      "severity": "medium",
+      "title": "Short title of the issue",
+      "description": "Detailed explanation of the issue",
+      "suggestion": "How to fix it"
+    }
+  ]
+}
+
+Severity levels: "critical", "high", "medium", "low", "info"
+
+If there are no issues, return: {"findings": []}
+Do NOT invent issues. Only report genuine problems in the added and changed code.`
+
+// CustomSystemPrompt wraps user-supplied review instructions with the standard
+// JSON response contract.
+func CustomSystemPrompt(instructions string) string {
+	return fmt.Sprintf("You are a senior code reviewer.\n\n%s\n\n%s", instructions, responseContract)
+}
+
+// CustomPrompt builds the user prompt for a user-defined review pass.
+func CustomPrompt(passName, diffContext string) string {
+	return fmt.Sprintf(`Review the following code changes for the %q check described in your instructions.
+Focus only on the added and changed lines.
+
+%s
+
+Respond with JSON only.`, passName, diffContext)
+}
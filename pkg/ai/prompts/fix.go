@@ -0,0 +1,44 @@
+package prompts
+
+import "fmt"
+
+// NoFix is the response the model gives when it cannot propose a safe fix.
+const NoFix = "NO_FIX"
+
+const fixSystemPrompt = `You are a senior engineer writing the smallest correct fix for a reported code issue.
+
+You are given one finding and the current code around it. Each code line is
+prefixed with its line number and " | ". The numbers are not part of the code.
+
+Respond with a single unified diff hunk against the code as shown, and nothing else:
+- Start with a header of the form: @@ -<start>,<old count> +<start>,<new count> @@
+  where <start> is the line number of the first line the hunk covers.
+- Prefix unchanged lines with a space, removed lines with "-", added lines with "+".
+- Include at least one unchanged or removed line so the hunk can be located.
+- Keep the original indentation exactly. Do not include the line number prefixes.
+- Do not include file headers (---/+++), explanations or markdown.
+
+Change only what is needed to resolve the finding. If you cannot propose a
+safe, self-contained fix within the shown code, respond with exactly: ` + NoFix
+
+// FixSystemPrompt returns the system prompt for generating fix patches.
+func FixSystemPrompt() string {
+	return fixSystemPrompt
+}
+
+// FixPrompt builds the user prompt asking for a patch that resolves a finding.
+// The finding's fields come from the diff or from model output about it, so
+// they are fenced as untrusted. code is the numbered post-change source around
+// the finding, already fenced.
+func FixPrompt(file string, line int, title, description, suggestion, code string) string {
+	finding := fmt.Sprintf("%s at line %d: %s\n%s", file, line, title, description)
+	if suggestion != "" {
+		finding += "\nSuggested approach: " + suggestion
+	}
+	return fmt.Sprintf(`Finding:
+%s
+
+Code:
+%s
+Respond with the unified diff hunk only.`, FenceUntrusted(finding), code)
+}
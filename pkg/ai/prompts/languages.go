@@ -0,0 +1,106 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// languagePacks holds the built-in language-specific review points, keyed by
+// FileDiff.Language and then by pass name. Only the logic and convention
+// passes have language idioms to check; the semantic pass compares intent
+// with behaviour and is language-agnostic.
+var languagePacks = map[string]map[string]string{
+	"go": {
+		"logic": `- Goroutines that can block forever on a channel send/receive or never observe context cancellation (goroutine leaks).
+- Interface values holding a typed nil pointer compared against nil; functions returning a nil concrete pointer as an error.
+- Loop variables captured by goroutines or closures where the code targets Go versions before 1.22.
+- Unchecked errors, errors shadowed with :=, and deferred Close errors dropped on written files.
+- Maps written concurrently without synchronisation; sync.Mutex or sync.WaitGroup copied by value.
+- Slices appended to after being shared, aliasing the caller's backing array.`,
+		"convention": `- Errors wrapped with %w and context, not discarded or only logged; error strings lower-case without trailing punctuation.
+- context.Context as the first parameter, not stored in structs.
+- Exported identifiers documented; receiver names short and consistent.
+- panic used for ordinary error handling.`,
+	},
+	"python": {
+		"logic": `- Mutable default arguments (lists, dicts, sets) shared between calls.
+- Late-binding closures in loops and lambdas capturing the loop variable.
+- Bare or overly broad except clauses that swallow errors, including KeyboardInterrupt.
+- Comparisons to None, True or False with == instead of is; truthiness checks that treat 0 or "" as missing.
+- Files, sockets and locks opened without a with block.
+- Blocking calls inside async functions; coroutines called without await.`,
+		"convention": `- PEP 8 naming; type hints on new public functions.
+- f-strings or logging arguments instead of string concatenation in log calls.
+- Imports at module level, no wildcard imports.`,
+	},
+	"javascript": javascriptPack,
+	"typescript": {
+		"logic":      javascriptPack["logic"] + "\n- Type assertions (as, !) that hide possible null or undefined values; any leaking into typed code.",
+		"convention": javascriptPack["convention"] + "\n- Explicit types on exported functions; unknown instead of any for untrusted input.",
+	},
+	"java": {
+		"logic": `- equals/hashCode overridden inconsistently; strings or boxed numbers compared with ==.
+- Resources not closed with try-with-resources; exceptions swallowed in empty catch blocks.
+- Collections modified while iterated; unsynchronised shared mutable state.
+- Optional.get() without a presence check; unchecked nulls from maps and external calls.`,
+		"convention": `- Specific exception types instead of Exception or Throwable.
+- Immutable fields final; no raw generic types.`,
+	},
+	"rust": {
+		"logic": `- unwrap() or expect() on values that can fail at runtime outside tests.
+- unsafe blocks without a justified invariant; Send/Sync assumptions across threads.
+- Integer overflow and lossy as casts on untrusted sizes.
+- Holding a lock or RefCell borrow across an await point.`,
+		"convention": `- Errors propagated with ? and meaningful error types rather than String.
+- clone() used to silence the borrow checker where a reference would do.`,
+	},
+}
+
+// javascriptPack is shared by JavaScript and TypeScript.
+var javascriptPack = map[string]string{
+	"logic": `- Promises neither awaited nor returned, and async errors left unhandled.
+- == and != with type coercion; NaN comparisons.
+- Array.prototype.sort without a comparator on numbers; mutation of shared objects or props.
+- this lost in callbacks; var hoisting in loops with closures.`,
+	"convention": `- const and let instead of var; strict equality.
+- No console.log left in production code.`,
+}
+
+// languageAliases maps language identifiers to the pack they share.
+var languageAliases = map[string]string{
+	"javascriptreact": "javascript",
+	"typescriptreact": "typescript",
+}
+
+// PackLanguage returns the language whose pack files of language share, such
+// as "typescript" for "typescriptreact", or language itself.
+func PackLanguage(language string) string {
+	if alias, ok := languageAliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+// LanguageGuidance returns the built-in review points for the pass in files
+// of the given language, or "" if there are none.
+func LanguageGuidance(language, pass string) string {
+	return languagePacks[PackLanguage(language)][pass]
+}
+
+// LanguageSection formats per-language review points for appending to a
+// pass's system prompt. languages and guidance are parallel slices; entries
+// with empty guidance are left out. Returns "" if nothing remains.
+func LanguageSection(languages, guidance []string) string {
+	var b strings.Builder
+	for i, lang := range languages {
+		text := strings.TrimSpace(guidance[i])
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\nIn %s files, also check for:\n%s", lang, text)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\n\nLanguage-specific checks for the languages in this change:" + b.String()
+}
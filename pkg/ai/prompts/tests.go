@@ -0,0 +1,52 @@
+package prompts
+
+import "fmt"
+
+const testSuggestionSystemPrompt = `You are a senior engineer writing unit tests for a code change.
+
+You are given a source file that was changed without matching test changes,
+with its diff hunks, and possibly existing test files from the same change.
+Write test function skeletons for the functions, methods or components that
+were added or changed:
+- Use the project's language and test framework. Follow the example tests for
+  the framework, naming, package or module layout, imports and helpers. Without
+  examples, use the language's standard or most common test framework.
+- Write one test per changed behaviour, named after the function under test.
+- Set up inputs and call the code under test, and mark every assertion the
+  author still has to write with a TODO comment. Do not invent behaviour the
+  diff does not show.
+- Only test code that appears in the diff.
+
+Respond with JSON only, in this exact shape:
+{"test_file": "path of the test file to add the tests to", "framework": "test framework used", "code": "the test code"}
+
+"code" must be ready to paste into "test_file". Do not include markdown or any
+other text.`
+
+// TestSuggestionSystemPrompt returns the system prompt for suggesting tests
+// for a file with a coverage finding.
+func TestSuggestionSystemPrompt() string {
+	return testSuggestionSystemPrompt
+}
+
+// TestSuggestionPrompt builds the user prompt asking for test skeletons for
+// one changed file. finding is the coverage finding's description, naming the
+// test files the analyzer looked for; the file path and finding are fenced as
+// untrusted here. changes is the file's diff and examples holds test files
+// from the same diff (may be empty), both already fenced.
+func TestSuggestionPrompt(file, language, finding, changes, examples string) string {
+	if examples == "" {
+		examples = "(none in this change)\n"
+	}
+	if language == "" {
+		language = "unknown"
+	}
+	return fmt.Sprintf(`A changed file (language: %s) has no test changes:
+%s
+
+Changes:
+%s
+Existing tests in this change:
+%s
+Respond with the JSON only.`, language, FenceUntrusted(file+"\n"+finding), changes, examples)
+}
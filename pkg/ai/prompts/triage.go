@@ -0,0 +1,45 @@
+package prompts
+
+import "fmt"
+
+// Triage verdicts the model may return.
+const (
+	VerdictTruePositive  = "true_positive"
+	VerdictFalsePositive = "false_positive"
+	VerdictUncertain     = "uncertain"
+)
+
+const triageSystemPrompt = `You are a senior security engineer triaging the output of static analysis tools.
+
+You are given one finding reported by a heuristic analyzer and the changed code
+around it. Each code line is prefixed with its line number and " | ". The
+numbers are not part of the code.
+
+Decide whether the finding is a real problem in this code (true positive) or a
+false alarm, such as a placeholder credential, a test fixture, a pattern used
+safely, or a match on a comment or string that is never executed.
+
+Respond with JSON only, in this exact shape:
+{"verdict": "true_positive" | "false_positive" | "uncertain", "confidence": 0.0-1.0, "reasoning": "one or two sentences"}
+
+"confidence" is how sure you are of the verdict. Use "uncertain" when the shown
+code is not enough to decide. Do not include markdown or any other text.`
+
+// TriageSystemPrompt returns the system prompt for triaging static findings.
+func TriageSystemPrompt() string {
+	return triageSystemPrompt
+}
+
+// TriagePrompt builds the user prompt asking for a verdict on one static
+// finding. The file, title and description are derived from the diff and are
+// fenced as untrusted; code is the numbered post-change source around the
+// finding, already fenced.
+func TriagePrompt(analyzer, file string, line int, severity, title, description, code string) string {
+	finding := fmt.Sprintf("%s at line %d: %s\n%s", file, line, title, description)
+	return fmt.Sprintf(`Finding from the %s analyzer [%s]:
+%s
+
+Code:
+%s
+Respond with the JSON verdict only.`, analyzer, severity, FenceUntrusted(finding), code)
+}
@@ -0,0 +1,40 @@
+package prompts
+
+import "fmt"
+
+const walkthroughSystemPrompt = `You are a senior engineer writing a short walkthrough of a pull request for its reviewers.
+Explain what the change does, not whether it is correct. Be concrete and brief.
+
+You MUST respond with valid JSON only. No markdown, no commentary outside the JSON.
+
+Response format:
+{
+  "summary": "Two to four sentences on what the change does and why",
+  "files": [
+    {"file": "path/to/file.go", "summary": "One sentence on what changed in this file"}
+  ],
+  "risks": [
+    {"file": "path/to/file.go", "reason": "Why this area deserves the closest review"}
+  ]
+}
+
+List at most 3 risks, most important first. Use "risks": [] if nothing stands out.
+Only mention files that appear in the change.`
+
+// WalkthroughSystemPrompt returns the system prompt for the PR walkthrough.
+func WalkthroughSystemPrompt() string {
+	return walkthroughSystemPrompt
+}
+
+// WalkthroughPrompt builds the user prompt for the PR walkthrough. fileList
+// names every changed file; diffContext may cover only part of the change.
+// Both must be fenced.
+func WalkthroughPrompt(fileList, diffContext string) string {
+	return fmt.Sprintf(`Write a walkthrough of the following change.
+
+Changed files:
+%s
+%s
+
+Respond with JSON only.`, fileList, diffContext)
+}
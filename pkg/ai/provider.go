@@ -3,7 +3,12 @@
 // as well as Anthropic's Claude API.
 package ai
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
 
 // ProviderType identifies the LLM provider protocol.
 type ProviderType string
@@ -11,6 +16,7 @@ type ProviderType string
 const (
 	ProviderOpenAICompatible ProviderType = "openai-compatible"
 	ProviderAnthropic        ProviderType = "anthropic"
+	ProviderReplay           ProviderType = "replay" // serves responses from a recorded cassette
 )
 
 // ProviderConfig holds the configuration for connecting to an LLM provider.
@@ -23,16 +29,115 @@ type ProviderConfig struct {
 
 // CompletionOpts configures a single LLM completion request.
 type CompletionOpts struct {
-	MaxTokens    int     `json:"max_tokens,omitempty"`
-	Temperature  float64 `json:"temperature,omitempty"`
-	SystemPrompt string  `json:"system_prompt,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"` // nil = provider default
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+}
+
+// Usage is the number of tokens a completion consumed, as reported by the
+// provider. Zero values mean the provider did not report usage.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+	}
+}
+
+// Completion is the result of a single LLM call.
+type Completion struct {
+	Text  string
+	Usage Usage
 }
 
 // LLMProvider abstracts communication with an LLM backend.
 type LLMProvider interface {
-	// Complete sends a prompt to the LLM and returns the response text.
-	Complete(ctx context.Context, prompt string, opts CompletionOpts) (string, error)
+	// Complete sends a prompt to the LLM and returns the response text and
+	// the tokens it consumed.
+	Complete(ctx context.Context, prompt string, opts CompletionOpts) (Completion, error)
 
 	// Available checks whether the provider endpoint is configured and reachable.
 	Available(ctx context.Context) bool
 }
+
+// Embedder turns texts into embedding vectors, for retrieving related
+// repository code.
+type Embedder interface {
+	// Embed returns one vector per text, in the order given.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ErrToolsUnsupported is returned (wrapped) by CompleteWithTools when the
+// provider, or one it wraps, cannot call tools.
+var ErrToolsUnsupported = errors.New("provider does not support tool calling")
+
+// Message is one turn of a tool-calling conversation.
+type Message struct {
+	Role       string     `json:"role"` // "user", "assistant" or "tool"
+	Content    string     `json:"content,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // tools requested by an assistant turn
+	ToolCallID string     `json:"tool_call_id,omitempty"` // call a tool turn answers
+}
+
+// ToolCall is a model's request to run a tool.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON object
+}
+
+// Tool describes a function the model may call.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"` // JSON Schema of the arguments object
+}
+
+// ToolCompletion is the result of a tool-calling LLM call. ToolCalls is set
+// when the model asks for tools to be run instead of answering.
+type ToolCompletion struct {
+	Completion
+	ToolCalls []ToolCall
+}
+
+// ToolProvider is implemented by providers that support function calling.
+// opts.SystemPrompt is sent ahead of messages.
+type ToolProvider interface {
+	CompleteWithTools(ctx context.Context, messages []Message, tools []Tool, opts CompletionOpts) (ToolCompletion, error)
+}
+
+// CompleteWithTools sends a tool-calling request to p, or returns
+// ErrToolsUnsupported if p does not implement ToolProvider. Provider wrappers
+// use it to forward tool-calling requests.
+func CompleteWithTools(ctx context.Context, p LLMProvider, messages []Message, tools []Tool, opts CompletionOpts) (ToolCompletion, error) {
+	tp, ok := p.(ToolProvider)
+	if !ok {
+		return ToolCompletion{}, ErrToolsUnsupported
+	}
+	return tp.CompleteWithTools(ctx, messages, tools, opts)
+}
+
+// ProviderError is returned by providers when the endpoint answers with a
+// non-success HTTP status. It carries enough detail for callers to decide
+// whether the request is worth retrying.
+type ProviderError struct {
+	StatusCode int           // HTTP status returned by the endpoint
+	RetryAfter time.Duration // Delay requested by the endpoint via Retry-After, if any
+	Message    string        // Human-readable description of the failure
+}
+
+// Error implements the error interface.
+func (e *ProviderError) Error() string {
+	return e.Message
+}
+
+// Retryable reports whether the failure is transient: rate limiting,
+// provider overload, or a server-side error.
+func (e *ProviderError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
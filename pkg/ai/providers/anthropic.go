@@ -0,0 +1,209 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+const (
+	// AnthropicAPIVersion is the value sent in the anthropic-version header.
+	AnthropicAPIVersion = "2023-06-01"
+
+	// defaultAnthropicMaxTokens is used when the caller does not set MaxTokens,
+	// because the Messages API requires max_tokens on every request.
+	defaultAnthropicMaxTokens = 2048
+)
+
+// AnthropicProvider implements ai.LLMProvider for Anthropic's Messages API.
+type AnthropicProvider struct {
+	config ai.ProviderConfig
+	client *http.Client
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API.
+func NewAnthropicProvider(cfg ai.ProviderConfig, timeout time.Duration) *AnthropicProvider {
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	return &AnthropicProvider{
+		config: cfg,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// messagesRequest is the Anthropic Messages API request body.
+type messagesRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// messagesResponse is the Anthropic Messages API response body.
+type messagesResponse struct {
+	Type    string `json:"type"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *anthropicError `json:"error"`
+}
+
+// anthropicError is the error object returned by the Anthropic API.
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Complete sends a prompt to the Anthropic Messages API and returns the response
+// text with the token usage the API reports.
+func (p *AnthropicProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	reqBody := messagesRequest{
+		Model:     p.config.Model,
+		System:    opts.SystemPrompt,
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens: defaultAnthropicMaxTokens,
+	}
+	if opts.MaxTokens > 0 {
+		reqBody.MaxTokens = opts.MaxTokens
+	}
+	reqBody.Temperature = opts.Temperature
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: marshaling request: %w", err)
+	}
+
+	url := p.config.Endpoint + "/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: sending request to %s: %w", url, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return ai.Completion{}, anthropicStatusError(resp, respBody)
+	}
+
+	var msgResp messagesResponse
+	if err := json.Unmarshal(respBody, &msgResp); err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: decoding response: %w", err)
+	}
+
+	if msgResp.Error != nil {
+		return ai.Completion{}, fmt.Errorf("ai: provider error: %s: %s", msgResp.Error.Type, msgResp.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, block := range msgResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return ai.Completion{}, fmt.Errorf("ai: provider returned no text content (stop_reason %q)", msgResp.StopReason)
+	}
+
+	return ai.Completion{
+		Text: text.String(),
+		Usage: ai.Usage{
+			PromptTokens:     msgResp.Usage.InputTokens,
+			CompletionTokens: msgResp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// Available checks if the Anthropic endpoint is reachable and the API key is accepted.
+func (p *AnthropicProvider) Available(ctx context.Context) bool {
+	if p.config.Endpoint == "" || p.config.Model == "" || p.config.APIKey == "" {
+		return false
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	url := p.config.Endpoint + "/models"
+	req, err := http.NewRequestWithContext(checkCtx, http.MethodGet, url, nil)
+	if err != nil {
+		slog.Debug("ai: availability check failed", "error", err)
+		return false
+	}
+	p.setHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		slog.Debug("ai: endpoint unreachable", "endpoint", p.config.Endpoint, "error", err)
+		return false
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	return resp.StatusCode == http.StatusOK
+}
+
+// setHeaders adds the Anthropic authentication and versioning headers.
+func (p *AnthropicProvider) setHeaders(req *http.Request) {
+	req.Header.Set("anthropic-version", AnthropicAPIVersion)
+	if p.config.APIKey != "" {
+		req.Header.Set("x-api-key", p.config.APIKey)
+	}
+}
+
+// anthropicStatusError converts a non-200 Anthropic response into an *ai.ProviderError.
+func anthropicStatusError(resp *http.Response, body []byte) error {
+	var envelope struct {
+		Error *anthropicError `json:"error"`
+	}
+	msg := truncate(string(body), 200)
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil {
+		msg = envelope.Error.Message
+	}
+
+	status := resp.StatusCode
+	perr := &ai.ProviderError{
+		StatusCode: status,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	switch status {
+	case http.StatusTooManyRequests:
+		perr.Message = fmt.Sprintf("ai: rate limited by provider (HTTP 429): %s", msg)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		perr.Message = fmt.Sprintf("ai: provider rejected credentials (HTTP %d): %s", status, msg)
+	case 529:
+		perr.Message = fmt.Sprintf("ai: provider overloaded (HTTP 529): %s", msg)
+	default:
+		perr.Message = fmt.Sprintf("ai: provider returned HTTP %d: %s", status, msg)
+	}
+	return perr
+}
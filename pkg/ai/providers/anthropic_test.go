@@ -0,0 +1,150 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+func TestAnthropicProvider_Complete(t *testing.T) {
+	var gotPath, gotKey, gotVersion string
+	var gotReq messagesRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-api-key")
+		gotVersion = r.Header.Get("anthropic-version")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotReq)
+		_, _ = w.Write([]byte(`{"type":"message","content":[{"type":"text","text":"{\"findings\": []}"}],"stop_reason":"end_turn","usage":{"input_tokens":120,"output_tokens":8}}`))
+	}))
+	defer server.Close()
+
+	p := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "claude-test", APIKey: "sk-ant"}, 0)
+	temperature := 0.2
+	got, err := p.Complete(context.Background(), "review this", ai.CompletionOpts{
+		MaxTokens:    512,
+		Temperature:  &temperature,
+		SystemPrompt: "you are a reviewer",
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if got.Text != `{"findings": []}` {
+		t.Errorf("unexpected response text: %q", got.Text)
+	}
+	if got.Usage != (ai.Usage{PromptTokens: 120, CompletionTokens: 8}) {
+		t.Errorf("unexpected usage: %+v", got.Usage)
+	}
+	if gotPath != "/messages" {
+		t.Errorf("unexpected path: %s", gotPath)
+	}
+	if gotKey != "sk-ant" {
+		t.Errorf("unexpected x-api-key header: %q", gotKey)
+	}
+	if gotVersion != AnthropicAPIVersion {
+		t.Errorf("unexpected anthropic-version header: %q", gotVersion)
+	}
+	if gotReq.System != "you are a reviewer" {
+		t.Errorf("expected system prompt in top-level field, got %q", gotReq.System)
+	}
+	if gotReq.MaxTokens != 512 {
+		t.Errorf("expected max_tokens 512, got %d", gotReq.MaxTokens)
+	}
+	if gotReq.Temperature == nil || *gotReq.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", gotReq.Temperature)
+	}
+	if len(gotReq.Messages) != 1 || gotReq.Messages[0].Role != "user" {
+		t.Errorf("expected a single user message, got %+v", gotReq.Messages)
+	}
+}
+
+func TestAnthropicProvider_Complete_DefaultMaxTokens(t *testing.T) {
+	var gotReq messagesRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotReq)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m", APIKey: "k"}, 0)
+	if _, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{}); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if gotReq.MaxTokens != defaultAnthropicMaxTokens {
+		t.Errorf("expected default max_tokens %d, got %d", defaultAnthropicMaxTokens, gotReq.MaxTokens)
+	}
+	if gotReq.Temperature != nil {
+		t.Errorf("expected temperature to be omitted, got %v", *gotReq.Temperature)
+	}
+}
+
+func TestAnthropicProvider_Complete_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"rate limited", http.StatusTooManyRequests, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`, "rate limited"},
+		{"bad key", http.StatusUnauthorized, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, "invalid x-api-key"},
+		{"overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, "overloaded"},
+		{"non-json body", http.StatusBadGateway, `upstream down`, "HTTP 502: upstream down"},
+		{"no text content", http.StatusOK, `{"content":[],"stop_reason":"max_tokens"}`, "no text content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m", APIKey: "k"}, 0)
+			_, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestAnthropicProvider_Available(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("x-api-key") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	good := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m", APIKey: "good"}, 0)
+	if !good.Available(context.Background()) {
+		t.Error("expected Available() to return true with a valid key")
+	}
+
+	bad := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m", APIKey: "bad"}, 0)
+	if bad.Available(context.Background()) {
+		t.Error("expected Available() to return false when the key is rejected")
+	}
+
+	noKey := NewAnthropicProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	if noKey.Available(context.Background()) {
+		t.Error("expected Available() to return false without an API key")
+	}
+}
@@ -0,0 +1,277 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// Default cache settings used when CacheConfig fields are left at zero.
+const (
+	DefaultCacheTTL     = 7 * 24 * time.Hour
+	DefaultCacheMaxSize = 100 << 20 // 100 MiB
+)
+
+// CacheConfig configures a CacheProvider.
+type CacheConfig struct {
+	Dir     string        // Directory holding cached responses (created if missing)
+	Model   string        // Model name, part of the cache key
+	TTL     time.Duration // Entries older than this are ignored and removed
+	MaxSize int64         // Total size in bytes before the oldest entries are evicted
+}
+
+// CacheProvider wraps an ai.LLMProvider with a disk-backed response cache.
+// Responses are keyed by a hash of the model, prompts and completion options,
+// so re-running a review over the same diff does not pay for the same calls twice.
+type CacheProvider struct {
+	next   ai.LLMProvider
+	config CacheConfig
+	now    func() time.Time
+
+	mu     sync.Mutex // serialises writes and eviction
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry is the on-disk format of a cached response.
+type cacheEntry struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Model     string        `json:"model"`
+	Response  string        `json:"response"`
+	ToolCalls []ai.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// NewCacheProvider wraps next with a response cache stored under cfg.Dir.
+func NewCacheProvider(next ai.LLMProvider, cfg CacheConfig) (*CacheProvider, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("ai: cache directory must be set")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultCacheTTL
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultCacheMaxSize
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ai: creating cache directory %s: %w", cfg.Dir, err)
+	}
+	return &CacheProvider{
+		next:   next,
+		config: cfg,
+		now:    time.Now,
+	}, nil
+}
+
+// Complete returns a cached response when one exists and has not expired;
+// otherwise it calls the wrapped provider and stores a successful response.
+// Cache hits report zero usage because no tokens were spent on them.
+func (p *CacheProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	key := p.key(prompt, opts)
+
+	if entry, ok := p.load(key); ok {
+		p.hits.Add(1)
+		slog.Debug("ai: cache hit", "key", key[:12])
+		return ai.Completion{Text: entry.Response}, nil
+	}
+	p.misses.Add(1)
+
+	resp, err := p.next.Complete(ctx, prompt, opts)
+	if err != nil {
+		return ai.Completion{}, err
+	}
+
+	if err := p.store(key, resp.Text, nil); err != nil {
+		slog.Debug("ai: failed to write cache entry", "error", err)
+	}
+	return resp, nil
+}
+
+// CompleteWithTools caches tool-calling turns like Complete, keyed by the
+// whole conversation and the tool definitions.
+func (p *CacheProvider) CompleteWithTools(ctx context.Context, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
+	key := toolRequestKey(p.config.Model, messages, tools, opts)
+
+	if entry, ok := p.load(key); ok {
+		p.hits.Add(1)
+		slog.Debug("ai: cache hit", "key", key[:12])
+		return ai.ToolCompletion{Completion: ai.Completion{Text: entry.Response}, ToolCalls: entry.ToolCalls}, nil
+	}
+	p.misses.Add(1)
+
+	resp, err := ai.CompleteWithTools(ctx, p.next, messages, tools, opts)
+	if err != nil {
+		return ai.ToolCompletion{}, err
+	}
+
+	if err := p.store(key, resp.Text, resp.ToolCalls); err != nil {
+		slog.Debug("ai: failed to write cache entry", "error", err)
+	}
+	return resp, nil
+}
+
+// Available delegates to the wrapped provider.
+func (p *CacheProvider) Available(ctx context.Context) bool {
+	return p.next.Available(ctx)
+}
+
+// Stats returns the number of cache hits and misses since creation.
+func (p *CacheProvider) Stats() (hits, misses int) {
+	return int(p.hits.Load()), int(p.misses.Load())
+}
+
+// key derives the cache key from everything that influences the response.
+func (p *CacheProvider) key(prompt string, opts ai.CompletionOpts) string {
+	return requestKey(p.config.Model, prompt, opts)
+}
+
+// requestKey hashes a completion request. The model may be empty when the
+// key should not depend on it.
+func requestKey(model, prompt string, opts ai.CompletionOpts) string {
+	payload, _ := json.Marshal(struct {
+		Model        string   `json:"model"`
+		SystemPrompt string   `json:"system_prompt"`
+		Prompt       string   `json:"prompt"`
+		MaxTokens    int      `json:"max_tokens"`
+		Temperature  *float64 `json:"temperature"`
+	}{model, opts.SystemPrompt, prompt, opts.MaxTokens, opts.Temperature})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// toolRequestKey hashes a tool-calling request.
+func toolRequestKey(model string, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) string {
+	payload, _ := json.Marshal(struct {
+		Model        string       `json:"model"`
+		SystemPrompt string       `json:"system_prompt"`
+		Messages     []ai.Message `json:"messages"`
+		Tools        []ai.Tool    `json:"tools"`
+		MaxTokens    int          `json:"max_tokens"`
+		Temperature  *float64     `json:"temperature"`
+	}{model, opts.SystemPrompt, messages, tools, opts.MaxTokens, opts.Temperature})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *CacheProvider) path(key string) string {
+	return filepath.Join(p.config.Dir, key+".json")
+}
+
+// load reads a cache entry, discarding it if it is unreadable or expired.
+func (p *CacheProvider) load(key string) (cacheEntry, bool) {
+	data, err := os.ReadFile(p.path(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		_ = os.Remove(p.path(key))
+		return cacheEntry{}, false
+	}
+	if p.now().Sub(entry.CreatedAt) > p.config.TTL {
+		_ = os.Remove(p.path(key))
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store writes a cache entry atomically and evicts old entries if the cache
+// has grown beyond its size limit.
+func (p *CacheProvider) store(key, response string, toolCalls []ai.ToolCall) error {
+	data, err := json.Marshal(cacheEntry{
+		CreatedAt: p.now(),
+		Model:     p.config.Model,
+		Response:  response,
+		ToolCalls: toolCalls,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tmp, err := os.CreateTemp(p.config.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()           //nolint:errcheck
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p.path(key)); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+
+	return p.evict()
+}
+
+// evict removes expired entries, then the oldest entries until the cache fits
+// within MaxSize. Callers must hold p.mu.
+func (p *CacheProvider) evict() error {
+	dirEntries, err := os.ReadDir(p.config.Dir)
+	if err != nil {
+		return err
+	}
+
+	type fileInfo struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []fileInfo
+	var total int64
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(p.config.Dir, de.Name())
+		if p.now().Sub(info.ModTime()) > p.config.TTL {
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, fileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if total <= p.config.MaxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= p.config.MaxSize {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	return nil
+}
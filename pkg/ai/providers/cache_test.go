@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// countingProvider echoes the prompt and counts calls.
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (c *countingProvider) Complete(_ context.Context, prompt string, _ ai.CompletionOpts) (ai.Completion, error) {
+	c.calls++
+	if c.err != nil {
+		return ai.Completion{}, c.err
+	}
+	return ai.Completion{Text: "response to " + prompt}, nil
+}
+
+func (c *countingProvider) Available(_ context.Context) bool { return true }
+
+func TestCacheProvider_HitAndMiss(t *testing.T) {
+	next := &countingProvider{}
+	p, err := NewCacheProvider(next, CacheConfig{Dir: t.TempDir(), Model: "m"})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	temperature := 0.1
+	opts := ai.CompletionOpts{SystemPrompt: "sys", MaxTokens: 100, Temperature: &temperature}
+
+	first, err := p.Complete(ctx, "diff", opts)
+	if err != nil {
+		t.Fatalf("first Complete: %v", err)
+	}
+	second, err := p.Complete(ctx, "diff", opts)
+	if err != nil {
+		t.Fatalf("second Complete: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("cached response differs: %q vs %q", first, second)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected 1 upstream call, got %d", next.calls)
+	}
+	hits, misses := p.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestCacheProvider_KeyIncludesOptions(t *testing.T) {
+	next := &countingProvider{}
+	p, err := NewCacheProvider(next, CacheConfig{Dir: t.TempDir(), Model: "m"})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	_, _ = p.Complete(ctx, "diff", ai.CompletionOpts{SystemPrompt: "a"})
+	_, _ = p.Complete(ctx, "diff", ai.CompletionOpts{SystemPrompt: "b"})
+	warm, zero := 0.5, 0.0
+	_, _ = p.Complete(ctx, "diff", ai.CompletionOpts{SystemPrompt: "a", Temperature: &warm})
+	_, _ = p.Complete(ctx, "diff", ai.CompletionOpts{SystemPrompt: "a", Temperature: &zero})
+
+	if next.calls != 4 {
+		t.Errorf("expected distinct options to miss the cache, got %d upstream calls", next.calls)
+	}
+
+	other, err := NewCacheProvider(next, CacheConfig{Dir: p.config.Dir, Model: "other"})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+	_, _ = other.Complete(ctx, "diff", ai.CompletionOpts{SystemPrompt: "a"})
+	if next.calls != 5 {
+		t.Errorf("expected a different model to miss the cache, got %d upstream calls", next.calls)
+	}
+}
+
+func TestCacheProvider_ExpiredEntriesIgnored(t *testing.T) {
+	next := &countingProvider{}
+	p, err := NewCacheProvider(next, CacheConfig{Dir: t.TempDir(), Model: "m", TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	_, _ = p.Complete(context.Background(), "diff", ai.CompletionOpts{})
+
+	p.now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, _ = p.Complete(context.Background(), "diff", ai.CompletionOpts{})
+
+	if next.calls != 2 {
+		t.Errorf("expected expired entry to be refetched, got %d upstream calls", next.calls)
+	}
+}
+
+func TestCacheProvider_ErrorsNotCached(t *testing.T) {
+	next := &countingProvider{err: fmt.Errorf("boom")}
+	p, err := NewCacheProvider(next, CacheConfig{Dir: t.TempDir(), Model: "m"})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Complete(context.Background(), "diff", ai.CompletionOpts{}); err == nil {
+			t.Fatal("expected error to propagate")
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("expected failed responses not to be cached, got %d upstream calls", next.calls)
+	}
+}
+
+func TestCacheProvider_EvictsOldestOverMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingProvider{}
+	p, err := NewCacheProvider(next, CacheConfig{Dir: dir, Model: "m", MaxSize: 400})
+	if err != nil {
+		t.Fatalf("NewCacheProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, _ = p.Complete(ctx, fmt.Sprintf("prompt-%d-%s", i, strings.Repeat("x", 50)), ai.CompletionOpts{})
+	}
+
+	var total int64
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if filepath.Ext(e.Name()) != ".json" {
+			t.Errorf("unexpected leftover file %s", e.Name())
+		}
+		total += info.Size()
+	}
+	if total > 400 {
+		t.Errorf("cache size %d exceeds MaxSize 400", total)
+	}
+	if len(entries) == 0 {
+		t.Error("expected the most recent entries to be kept")
+	}
+}
+
+func TestNewCacheProvider_RequiresDir(t *testing.T) {
+	if _, err := NewCacheProvider(&countingProvider{}, CacheConfig{}); err == nil {
+		t.Error("expected error when no cache directory is configured")
+	}
+}
@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// cassetteVersion is the on-disk format version written by recording providers.
+const cassetteVersion = 1
+
+// Cassette is a recorded set of LLM interactions.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded prompt/response pair. Key is the hash the
+// request is replayed by; the other request fields are kept for readability.
+type Interaction struct {
+	Key          string        `json:"key"`
+	SystemPrompt string        `json:"system_prompt,omitempty"`
+	Prompt       string        `json:"prompt"`
+	Messages     []ai.Message  `json:"messages,omitempty"` // tool-calling conversation, instead of Prompt
+	Tools        []string      `json:"tools,omitempty"`    // names of the tools offered
+	MaxTokens    int           `json:"max_tokens,omitempty"`
+	Temperature  *float64      `json:"temperature,omitempty"`
+	Response     string        `json:"response"`
+	ToolCalls    []ai.ToolCall `json:"tool_calls,omitempty"`
+	Usage        ai.Usage      `json:"usage"`
+}
+
+// CassetteProvider records LLM interactions to a cassette file or replays
+// them from one. Requests are matched by a hash of the prompts and completion
+// options; the model is not part of the key, so a cassette recorded against
+// one backend replays regardless of the configured model.
+type CassetteProvider struct {
+	next  ai.LLMProvider // nil in replay mode
+	path  string
+	mu    sync.Mutex
+	byKey map[string]Interaction
+}
+
+// NewRecordingProvider wraps next and writes every successful interaction to
+// the cassette at path. An existing cassette is extended, so several runs can
+// contribute to one file.
+func NewRecordingProvider(next ai.LLMProvider, path string) (*CassetteProvider, error) {
+	byKey := make(map[string]Interaction)
+	if _, err := os.Stat(path); err == nil {
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, in := range c.Interactions {
+			byKey[in.Key] = in
+		}
+	}
+	return &CassetteProvider{next: next, path: path, byKey: byKey}, nil
+}
+
+// NewReplayProvider serves responses from the cassette at path. Prompts that
+// were not recorded fail instead of reaching a model.
+func NewReplayProvider(path string) (*CassetteProvider, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]Interaction, len(c.Interactions))
+	for _, in := range c.Interactions {
+		byKey[in.Key] = in
+	}
+	return &CassetteProvider{path: path, byKey: byKey}, nil
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ai: reading cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("ai: parsing cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("ai: cassette %s has unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Complete replays the recorded response for the request or, when recording,
+// forwards it to the wrapped provider and saves the result.
+func (p *CassetteProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	key := requestKey("", prompt, opts)
+
+	if p.next == nil {
+		p.mu.Lock()
+		in, ok := p.byKey[key]
+		p.mu.Unlock()
+		if !ok {
+			return ai.Completion{}, fmt.Errorf("ai: replay: no recorded response for prompt %s in %s", key[:12], p.path)
+		}
+		return ai.Completion{Text: in.Response, Usage: in.Usage}, nil
+	}
+
+	resp, err := p.next.Complete(ctx, prompt, opts)
+	if err != nil {
+		return ai.Completion{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.byKey[key] = Interaction{
+		Key:          key,
+		SystemPrompt: opts.SystemPrompt,
+		Prompt:       prompt,
+		MaxTokens:    opts.MaxTokens,
+		Temperature:  opts.Temperature,
+		Response:     resp.Text,
+		Usage:        resp.Usage,
+	}
+	if err := p.save(); err != nil {
+		return ai.Completion{}, fmt.Errorf("ai: writing cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// CompleteWithTools replays or records a tool-calling turn like Complete,
+// matched by a hash of the whole conversation and the tool definitions.
+func (p *CassetteProvider) CompleteWithTools(ctx context.Context, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
+	key := toolRequestKey("", messages, tools, opts)
+
+	if p.next == nil {
+		p.mu.Lock()
+		in, ok := p.byKey[key]
+		p.mu.Unlock()
+		if !ok {
+			return ai.ToolCompletion{}, fmt.Errorf("ai: replay: no recorded response for tool turn %s in %s", key[:12], p.path)
+		}
+		return ai.ToolCompletion{Completion: ai.Completion{Text: in.Response, Usage: in.Usage}, ToolCalls: in.ToolCalls}, nil
+	}
+
+	resp, err := ai.CompleteWithTools(ctx, p.next, messages, tools, opts)
+	if err != nil {
+		return ai.ToolCompletion{}, err
+	}
+
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.byKey[key] = Interaction{
+		Key:          key,
+		SystemPrompt: opts.SystemPrompt,
+		Messages:     messages,
+		Tools:        names,
+		MaxTokens:    opts.MaxTokens,
+		Temperature:  opts.Temperature,
+		Response:     resp.Text,
+		ToolCalls:    resp.ToolCalls,
+		Usage:        resp.Usage,
+	}
+	if err := p.save(); err != nil {
+		return ai.ToolCompletion{}, fmt.Errorf("ai: writing cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// Available is always true when replaying and delegates when recording.
+func (p *CassetteProvider) Available(ctx context.Context) bool {
+	if p.next == nil {
+		return true
+	}
+	return p.next.Available(ctx)
+}
+
+// save writes the cassette atomically, ordered by key so re-recording the same
+// review produces a stable file. Callers must hold p.mu.
+func (p *CassetteProvider) save() error {
+	c := Cassette{Version: cassetteVersion, Interactions: make([]Interaction, 0, len(p.byKey))}
+	for _, in := range p.byKey {
+		c.Interactions = append(c.Interactions, in)
+	}
+	sort.Slice(c.Interactions, func(i, j int) bool {
+		return c.Interactions[i].Key < c.Interactions[j].Key
+	})
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(p.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(p.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()           //nolint:errcheck
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path)
+}
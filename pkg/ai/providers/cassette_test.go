@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "review.json")
+	next := &countingProvider{}
+
+	rec, err := NewRecordingProvider(next, path)
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+	ctx := context.Background()
+	opts := ai.CompletionOpts{SystemPrompt: "sys", MaxTokens: 100}
+	recorded, err := rec.Complete(ctx, "diff", opts)
+	if err != nil {
+		t.Fatalf("record Complete: %v", err)
+	}
+
+	replay, err := NewReplayProvider(path)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	got, err := replay.Complete(ctx, "diff", opts)
+	if err != nil {
+		t.Fatalf("replay Complete: %v", err)
+	}
+	if got != recorded {
+		t.Errorf("replayed %q, recorded %q", got, recorded)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected replay not to reach a provider, got %d calls", next.calls)
+	}
+	if !replay.Available(ctx) {
+		t.Error("replay provider should always be available")
+	}
+}
+
+func TestCassette_ReplayUnknownPromptFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	rec, err := NewRecordingProvider(&countingProvider{}, path)
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+	_, _ = rec.Complete(context.Background(), "diff", ai.CompletionOpts{SystemPrompt: "sys"})
+
+	replay, err := NewReplayProvider(path)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	temperature := 0.7
+	for _, tc := range []struct {
+		prompt string
+		opts   ai.CompletionOpts
+	}{
+		{"other diff", ai.CompletionOpts{SystemPrompt: "sys"}},
+		{"diff", ai.CompletionOpts{SystemPrompt: "other"}},
+		{"diff", ai.CompletionOpts{SystemPrompt: "sys", Temperature: &temperature}},
+	} {
+		_, err := replay.Complete(context.Background(), tc.prompt, tc.opts)
+		if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+			t.Errorf("expected unknown prompt error for %q %+v, got %v", tc.prompt, tc.opts, err)
+		}
+	}
+}
+
+func TestCassette_RecordingExtendsExistingCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		rec, err := NewRecordingProvider(&countingProvider{}, path)
+		if err != nil {
+			t.Fatalf("NewRecordingProvider: %v", err)
+		}
+		_, _ = rec.Complete(ctx, fmt.Sprintf("prompt-%d", i), ai.CompletionOpts{})
+		_, _ = rec.Complete(ctx, "shared", ai.CompletionOpts{})
+	}
+
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Errorf("expected 3 distinct interactions, got %d", len(c.Interactions))
+	}
+	for i := 1; i < len(c.Interactions); i++ {
+		if c.Interactions[i-1].Key > c.Interactions[i].Key {
+			t.Error("expected interactions ordered by key")
+		}
+	}
+}
+
+func TestCassette_ErrorsNotRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	rec, err := NewRecordingProvider(&countingProvider{err: fmt.Errorf("boom")}, path)
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+	if _, err := rec.Complete(context.Background(), "diff", ai.CompletionOpts{}); err == nil {
+		t.Fatal("expected error to propagate")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no cassette to be written for failed calls, stat err = %v", err)
+	}
+}
+
+func TestNewReplayProvider_RejectsBadCassette(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewReplayProvider(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"version": 99, "interactions": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReplayProvider(bad); err == nil {
+		t.Error("expected error for unsupported cassette version")
+	}
+}
+
+// toolCallingProvider asks for one tool call on every tool-calling request.
+type toolCallingProvider struct {
+	countingProvider
+}
+
+func (p *toolCallingProvider) CompleteWithTools(_ context.Context, messages []ai.Message, _ []ai.Tool, _ ai.CompletionOpts) (ai.ToolCompletion, error) {
+	p.calls++
+	return ai.ToolCompletion{
+		Completion: ai.Completion{Usage: ai.Usage{PromptTokens: len(messages)}},
+		ToolCalls:  []ai.ToolCall{{ID: "call_1", Name: "read_file", Arguments: `{"path":"main.go"}`}},
+	}, nil
+}
+
+func TestCassette_RecordThenReplayToolTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	next := &toolCallingProvider{}
+	rec, err := NewRecordingProvider(next, path)
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+
+	ctx := context.Background()
+	messages := []ai.Message{{Role: "user", Content: "diff"}}
+	tools := []ai.Tool{{Name: "read_file", Parameters: map[string]any{"type": "object"}}}
+	opts := ai.CompletionOpts{SystemPrompt: "sys"}
+	recorded, err := rec.CompleteWithTools(ctx, messages, tools, opts)
+	if err != nil {
+		t.Fatalf("record CompleteWithTools: %v", err)
+	}
+
+	replay, err := NewReplayProvider(path)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	got, err := replay.CompleteWithTools(ctx, messages, tools, opts)
+	if err != nil {
+		t.Fatalf("replay CompleteWithTools: %v", err)
+	}
+	if !reflect.DeepEqual(got, recorded) {
+		t.Errorf("replayed %+v, recorded %+v", got, recorded)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected replay not to reach a provider, got %d calls", next.calls)
+	}
+
+	more := append(messages, ai.Message{Role: "tool", ToolCallID: "call_1", Content: "package main"})
+	if _, err := replay.CompleteWithTools(ctx, more, tools, opts); err == nil {
+		t.Error("expected a conversation that was not recorded to fail")
+	}
+}
+
+func TestCassette_ToolTurnsNeedToolProvider(t *testing.T) {
+	rec, err := NewRecordingProvider(&countingProvider{}, filepath.Join(t.TempDir(), "review.json"))
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+	_, err = rec.CompleteWithTools(context.Background(), []ai.Message{{Role: "user", Content: "diff"}}, nil, ai.CompletionOpts{})
+	if !errors.Is(err, ai.ErrToolsUnsupported) {
+		t.Errorf("expected ErrToolsUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// OpenAIEmbedder implements ai.Embedder for OpenAI-compatible /embeddings
+// endpoints (Ollama, vLLM, LocalAI, OpenAI).
+type OpenAIEmbedder struct {
+	config ai.ProviderConfig
+	client *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder for an OpenAI-compatible endpoint.
+// cfg.Model names the embedding model.
+func NewOpenAIEmbedder(cfg ai.ProviderConfig, timeout time.Duration) *OpenAIEmbedder {
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	return &OpenAIEmbedder{
+		config: cfg,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// embeddingRequest is the OpenAI embeddings request body.
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingResponse is the OpenAI embeddings response body.
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Embed sends texts to the /embeddings endpoint in a single request.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(embeddingRequest{Model: e.config.Model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("ai: marshalling request: %w", err)
+	}
+
+	url := e.config.Endpoint + "/embeddings"
+	req, err := newPostRequest(ctx, url, e.config.APIKey, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ai: sending request to %s: %w", url, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ai: reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp, respBody)
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("ai: decoding response: %w", err)
+	}
+	if parsed.Error != nil {
+		return nil, fmt.Errorf("ai: provider error: %s", parsed.Error.Message)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("ai: provider returned %d embeddings for %d inputs", len(parsed.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index < 0 || d.Index >= len(texts) || vectors[d.Index] != nil {
+			return nil, fmt.Errorf("ai: provider returned an embedding for unexpected input %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
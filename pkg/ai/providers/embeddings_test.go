@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	var got embeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		// Out of order, as some servers answer.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}],"usage":{"prompt_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	e := NewOpenAIEmbedder(ai.ProviderConfig{Endpoint: server.URL, Model: "nomic-embed-text", APIKey: "key"}, 0)
+	vectors, err := e.Embed(context.Background(), []string{"func a()", "func b()"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if got.Model != "nomic-embed-text" || len(got.Input) != 2 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if want := [][]float32{{1, 0}, {0, 1}}; !reflect.DeepEqual(vectors, want) {
+		t.Errorf("Embed = %v, want %v", vectors, want)
+	}
+}
+
+func TestOpenAIEmbedder_RejectsMissingEmbeddings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	e := NewOpenAIEmbedder(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	if _, err := e.Embed(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("expected an error when fewer embeddings than inputs are returned")
+	}
+}
@@ -0,0 +1,161 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// FallbackProvider tries an ordered list of providers, sending each request
+// to the first one that is available and answers it. A provider that reports
+// itself unavailable, or fails a request (after its own retries) with a
+// connection error, a timeout, a rate limit or a server error, is skipped for
+// the rest of the provider's lifetime, so one outage does not add a timeout to
+// every call of a review. Other errors, such as a request rejected as too
+// large, only send that request on to the next provider.
+type FallbackProvider struct {
+	members []ai.ModelProvider
+	checks  []sync.Once // availability check of each member
+
+	mu       sync.Mutex
+	down     []bool
+	answered []bool
+	usage    []ai.Usage
+}
+
+// NewFallbackProvider returns a provider that falls back through members in
+// order. Member names identify the provider that answered in Answered.
+func NewFallbackProvider(members ...ai.ModelProvider) *FallbackProvider {
+	return &FallbackProvider{
+		members:  members,
+		checks:   make([]sync.Once, len(members)),
+		down:     make([]bool, len(members)),
+		answered: make([]bool, len(members)),
+		usage:    make([]ai.Usage, len(members)),
+	}
+}
+
+// Complete sends the request to each usable member in turn and returns the
+// first successful completion. Cancellation of ctx is returned immediately
+// rather than treated as a member failure.
+func (p *FallbackProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	return fallback(ctx, p, func(m ai.LLMProvider) (ai.Completion, error) {
+		return m.Complete(ctx, prompt, opts)
+	}, func(c ai.Completion) ai.Usage { return c.Usage })
+}
+
+// CompleteWithTools falls back like Complete. Members that cannot call tools
+// are passed over without being marked down; ErrToolsUnsupported is returned
+// if no usable member can.
+func (p *FallbackProvider) CompleteWithTools(ctx context.Context, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
+	return fallback(ctx, p, func(m ai.LLMProvider) (ai.ToolCompletion, error) {
+		return ai.CompleteWithTools(ctx, m, messages, tools, opts)
+	}, func(c ai.ToolCompletion) ai.Usage { return c.Usage })
+}
+
+// fallback runs call against each usable member in turn.
+func fallback[T any](ctx context.Context, p *FallbackProvider, call func(ai.LLMProvider) (T, error), usage func(T) ai.Usage) (T, error) {
+	var zero T
+	var errs []error
+	unsupported := false
+	for i, m := range p.members {
+		if !p.usable(ctx, i) {
+			continue
+		}
+		completion, err := call(m.Provider)
+		if err == nil {
+			p.mu.Lock()
+			p.answered[i] = true
+			p.usage[i] = p.usage[i].Add(usage(completion))
+			p.mu.Unlock()
+			return completion, nil
+		}
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		if errors.Is(err, ai.ErrToolsUnsupported) {
+			unsupported = true
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+		if !isRetryable(err) {
+			// The request was rejected, which says nothing about the
+			// member's health; later requests may still succeed.
+			if i < len(p.members)-1 {
+				slog.Warn("AI review: provider rejected request, trying the next one", "provider", m.Name, "error", err)
+			}
+			continue
+		}
+		if i < len(p.members)-1 {
+			slog.Warn("AI review: provider failed, falling back", "provider", m.Name, "error", err)
+		}
+		p.mu.Lock()
+		p.down[i] = true
+		p.mu.Unlock()
+	}
+	if len(errs) == 0 {
+		if unsupported {
+			return zero, ai.ErrToolsUnsupported
+		}
+		return zero, fmt.Errorf("ai: no provider available")
+	}
+	return zero, fmt.Errorf("ai: all providers failed: %w", errors.Join(errs...))
+}
+
+// Available reports whether any member is available.
+func (p *FallbackProvider) Available(ctx context.Context) bool {
+	for i := range p.members {
+		if p.usable(ctx, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// usable reports whether member i may be sent requests, checking its
+// availability the first time it is needed. The check runs outside p.mu, so
+// only callers waiting for the same member's first check are held up.
+func (p *FallbackProvider) usable(ctx context.Context, i int) bool {
+	p.checks[i].Do(func() {
+		if !p.members[i].Provider.Available(ctx) {
+			slog.Warn("AI review: provider unavailable", "provider", p.members[i].Name)
+			p.mu.Lock()
+			p.down[i] = true
+			p.mu.Unlock()
+		}
+	})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.down[i]
+}
+
+// Answered returns the names of the members that answered at least one
+// request, in fallback order.
+func (p *FallbackProvider) Answered() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var names []string
+	for i, ok := range p.answered {
+		if ok {
+			names = append(names, p.members[i].Name)
+		}
+	}
+	return names
+}
+
+// Usage returns the token usage of each member that answered, keyed by name.
+func (p *FallbackProvider) Usage() map[string]ai.Usage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage := make(map[string]ai.Usage)
+	for i, ok := range p.answered {
+		if ok {
+			usage[p.members[i].Name] = p.usage[i]
+		}
+	}
+	return usage
+}
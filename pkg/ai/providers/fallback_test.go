@@ -0,0 +1,169 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// stubProvider answers with text, or fails with err, and counts calls.
+type stubProvider struct {
+	text      string
+	err       error
+	available bool
+	calls     int
+	checks    int
+}
+
+func (s *stubProvider) Complete(_ context.Context, _ string, _ ai.CompletionOpts) (ai.Completion, error) {
+	s.calls++
+	if s.err != nil {
+		return ai.Completion{}, s.err
+	}
+	return ai.Completion{Text: s.text, Usage: ai.Usage{PromptTokens: 10, CompletionTokens: 2}}, nil
+}
+
+func (s *stubProvider) Available(_ context.Context) bool {
+	s.checks++
+	return s.available
+}
+
+func TestFallbackProvider_SkipsUnavailable(t *testing.T) {
+	onPrem := &stubProvider{text: "on-prem"}
+	cloud := &stubProvider{text: "cloud", available: true}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "on-prem", Provider: onPrem},
+		ai.ModelProvider{Name: "cloud", Provider: cloud},
+	)
+
+	if !p.Available(context.Background()) {
+		t.Fatal("expected the chain to be available while one member is")
+	}
+	for range 2 {
+		got, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+		if err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if got.Text != "cloud" {
+			t.Errorf("expected the fallback to answer, got %q", got.Text)
+		}
+	}
+	if onPrem.calls != 0 || onPrem.checks != 1 {
+		t.Errorf("expected the unavailable member checked once and never called, got %d checks, %d calls", onPrem.checks, onPrem.calls)
+	}
+	if got := p.Answered(); !reflect.DeepEqual(got, []string{"cloud"}) {
+		t.Errorf("Answered() = %v", got)
+	}
+	if got := p.Usage()["cloud"]; got != (ai.Usage{PromptTokens: 20, CompletionTokens: 4}) {
+		t.Errorf("unexpected usage: %+v", got)
+	}
+}
+
+func TestFallbackProvider_FallsBackOnError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	primary := &stubProvider{err: refused, available: true}
+	backup := &stubProvider{text: "backup", available: true}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "primary", Provider: primary},
+		ai.ModelProvider{Name: "backup", Provider: backup},
+	)
+
+	for range 3 {
+		got, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+		if err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if got.Text != "backup" {
+			t.Errorf("got %q", got.Text)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("expected the failed member to be skipped after its first error, got %d calls", primary.calls)
+	}
+	if got := p.Answered(); !reflect.DeepEqual(got, []string{"backup"}) {
+		t.Errorf("Answered() = %v", got)
+	}
+}
+
+func TestFallbackProvider_RejectedRequestKeepsMember(t *testing.T) {
+	primary := &stubProvider{err: &ai.ProviderError{StatusCode: http.StatusBadRequest, Message: "prompt too long"}, available: true}
+	backup := &stubProvider{text: "backup", available: true}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "primary", Provider: primary},
+		ai.ModelProvider{Name: "backup", Provider: backup},
+	)
+
+	if got, err := p.Complete(context.Background(), "huge prompt", ai.CompletionOpts{}); err != nil || got.Text != "backup" {
+		t.Fatalf("expected the rejected request to fall back, got %q, %v", got.Text, err)
+	}
+	primary.err, primary.text = nil, "primary"
+	if got, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{}); err != nil || got.Text != "primary" {
+		t.Errorf("expected the member to stay in the chain after a rejected request, got %q, %v", got.Text, err)
+	}
+}
+
+func TestFallbackProvider_AllFail(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "a", Provider: &stubProvider{err: refused, available: true}},
+		ai.ModelProvider{Name: "b", Provider: &stubProvider{available: false}},
+	)
+
+	_, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+	if !errors.Is(err, refused) {
+		t.Errorf("expected the member error to be wrapped, got %v", err)
+	}
+	if p.Available(context.Background()) {
+		t.Error("expected the chain to be unavailable once every member is down")
+	}
+	if got := p.Answered(); got != nil {
+		t.Errorf("expected no member to have answered, got %v", got)
+	}
+}
+
+func TestFallbackProvider_ReturnsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backup := &stubProvider{text: "backup", available: true}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "primary", Provider: &stubProvider{err: context.Canceled, available: true}},
+		ai.ModelProvider{Name: "backup", Provider: backup},
+	)
+
+	if _, err := p.Complete(ctx, "prompt", ai.CompletionOpts{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if backup.calls != 0 {
+		t.Error("expected no fallback after cancellation")
+	}
+}
+
+func TestFallbackProvider_ToolTurnsSkipMembersWithoutTools(t *testing.T) {
+	plain := &stubProvider{text: "plain", available: true}
+	tools := &toolCallingProvider{}
+	p := NewFallbackProvider(
+		ai.ModelProvider{Name: "plain", Provider: plain},
+		ai.ModelProvider{Name: "tools", Provider: tools},
+	)
+
+	resp, err := p.CompleteWithTools(context.Background(), []ai.Message{{Role: "user", Content: "diff"}}, nil, ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("CompleteWithTools: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || tools.calls != 1 {
+		t.Errorf("expected the tool-capable member to answer, got %+v", resp)
+	}
+	if got, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{}); err != nil || got.Text != "plain" {
+		t.Errorf("expected the member without tools to stay in the chain, got %q, %v", got.Text, err)
+	}
+
+	only := NewFallbackProvider(ai.ModelProvider{Name: "plain", Provider: plain})
+	if _, err := only.CompleteWithTools(context.Background(), nil, nil, ai.CompletionOpts{}); !errors.Is(err, ai.ErrToolsUnsupported) {
+		t.Errorf("expected ErrToolsUnsupported, got %v", err)
+	}
+}
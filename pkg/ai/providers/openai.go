@@ -9,6 +9,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/toyinlola/shipsafe/pkg/ai"
@@ -17,8 +18,9 @@ import (
 // OpenAIProvider implements ai.LLMProvider for any OpenAI-compatible API.
 // This covers Ollama, vLLM, LocalAI, OpenRouter, and OpenAI itself.
 type OpenAIProvider struct {
-	config ai.ProviderConfig
-	client *http.Client
+	config      ai.ProviderConfig
+	client      *http.Client
+	idleTimeout time.Duration // streams responses when set; see NewStreamingOpenAIProvider
 }
 
 // NewOpenAIProvider creates a provider for OpenAI-compatible endpoints.
@@ -34,100 +36,198 @@ func NewOpenAIProvider(cfg ai.ProviderConfig, timeout time.Duration) *OpenAIProv
 
 // chatRequest is the OpenAI chat completions request body.
 type chatRequest struct {
-	Model       string        `json:"model"`
-	Messages    []chatMessage `json:"messages"`
-	MaxTokens   int           `json:"max_tokens,omitempty"`
-	Temperature *float64      `json:"temperature,omitempty"`
-	Stream      bool          `json:"stream"`
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	Tools         []chatTool     `json:"tools,omitempty"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
 }
 
 type chatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// chatTool declares a function the model may call.
+type chatTool struct {
+	Type     string `json:"type"` // always "function"
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+// chatToolCall is a function call requested by the model.
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // always "function"
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
 }
 
 // chatResponse is the OpenAI chat completions response body.
 type chatResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content   string         `json:"content"`
+			ToolCalls []chatToolCall `json:"tool_calls"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	} `json:"error"`
 }
 
-// Complete sends a prompt to the OpenAI-compatible endpoint and returns the response.
-func (p *OpenAIProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (string, error) {
-	messages := make([]chatMessage, 0, 2)
+// Complete sends a prompt to the OpenAI-compatible endpoint and returns the
+// response with the token usage the endpoint reports.
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	resp, err := p.chat(ctx, []chatMessage{{Role: "user", Content: prompt}}, nil, opts)
+	return resp.Completion, err
+}
+
+// CompleteWithTools sends a conversation with function definitions. When the
+// model calls functions, they are returned in the completion's ToolCalls.
+func (p *OpenAIProvider) CompleteWithTools(ctx context.Context, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
+	chat := make([]chatMessage, len(messages))
+	for i, m := range messages {
+		chat[i] = chatMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, tc := range m.ToolCalls {
+			call := chatToolCall{ID: tc.ID, Type: "function"}
+			call.Function.Name = tc.Name
+			call.Function.Arguments = tc.Arguments
+			chat[i].ToolCalls = append(chat[i].ToolCalls, call)
+		}
+	}
+	defs := make([]chatTool, len(tools))
+	for i, t := range tools {
+		defs[i].Type = "function"
+		defs[i].Function.Name = t.Name
+		defs[i].Function.Description = t.Description
+		defs[i].Function.Parameters = t.Parameters
+	}
+	return p.chat(ctx, chat, defs, opts)
+}
+
+// chat sends a chat completions request, prefixed with the system prompt.
+func (p *OpenAIProvider) chat(ctx context.Context, messages []chatMessage, tools []chatTool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
 	if opts.SystemPrompt != "" {
-		messages = append(messages, chatMessage{Role: "system", Content: opts.SystemPrompt})
+		messages = append([]chatMessage{{Role: "system", Content: opts.SystemPrompt}}, messages...)
 	}
-	messages = append(messages, chatMessage{Role: "user", Content: prompt})
 
 	reqBody := chatRequest{
 		Model:    p.config.Model,
 		Messages: messages,
-		Stream:   false,
+		Tools:    tools,
+		Stream:   p.idleTimeout > 0,
+	}
+	if reqBody.Stream {
+		reqBody.StreamOptions = &streamOptions{IncludeUsage: true}
 	}
 	if opts.MaxTokens > 0 {
 		reqBody.MaxTokens = opts.MaxTokens
 	}
-	if opts.Temperature > 0 {
-		t := opts.Temperature
-		reqBody.Temperature = &t
-	}
+	reqBody.Temperature = opts.Temperature
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("ai: marshaling request: %w", err)
+		return ai.ToolCompletion{}, fmt.Errorf("ai: marshaling request: %w", err)
 	}
 
 	url := p.config.Endpoint + "/chat/completions"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return "", fmt.Errorf("ai: creating request: %w", err)
+	if reqBody.Stream {
+		return p.chatStream(ctx, url, body)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if p.config.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	req, err := newPostRequest(ctx, url, p.config.APIKey, body)
+	if err != nil {
+		return ai.ToolCompletion{}, err
 	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("ai: sending request to %s: %w", url, err)
+		return ai.ToolCompletion{}, fmt.Errorf("ai: sending request to %s: %w", url, err)
 	}
 	defer resp.Body.Close() //nolint:errcheck
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("ai: reading response: %w", err)
+		return ai.ToolCompletion{}, fmt.Errorf("ai: reading response: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return "", fmt.Errorf("ai: rate limited by provider (HTTP 429)")
-	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ai: provider returned HTTP %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+		return ai.ToolCompletion{}, statusError(resp, respBody)
 	}
 
 	var chatResp chatResponse
 	if err := json.Unmarshal(respBody, &chatResp); err != nil {
-		return "", fmt.Errorf("ai: decoding response: %w", err)
+		return ai.ToolCompletion{}, fmt.Errorf("ai: decoding response: %w", err)
 	}
 
 	if chatResp.Error != nil {
-		return "", fmt.Errorf("ai: provider error: %s", chatResp.Error.Message)
+		return ai.ToolCompletion{}, fmt.Errorf("ai: provider error: %s", chatResp.Error.Message)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("ai: provider returned no choices")
+		return ai.ToolCompletion{}, fmt.Errorf("ai: provider returned no choices")
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	msg := chatResp.Choices[0].Message
+	completion := ai.ToolCompletion{Completion: ai.Completion{
+		Text: msg.Content,
+		Usage: ai.Usage{
+			PromptTokens:     chatResp.Usage.PromptTokens,
+			CompletionTokens: chatResp.Usage.CompletionTokens,
+		},
+	}}
+	for _, tc := range msg.ToolCalls {
+		completion.ToolCalls = append(completion.ToolCalls, ai.ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	return completion, nil
+}
+
+// newPostRequest creates a JSON POST request to an OpenAI-compatible endpoint.
+func newPostRequest(ctx context.Context, url, apiKey string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("ai: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return req, nil
+}
+
+// statusError converts a non-200 response into a ProviderError.
+func statusError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &ai.ProviderError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Message:    "ai: rate limited by provider (HTTP 429)",
+		}
+	}
+	return &ai.ProviderError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Message:    fmt.Sprintf("ai: provider returned HTTP %d: %s", resp.StatusCode, truncate(string(body), 200)),
+	}
 }
 
 // Available checks if the provider endpoint is reachable by sending a lightweight request.
@@ -166,3 +266,20 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen] + "..."
 }
+
+// parseRetryAfter interprets a Retry-After header value, which may be either a
+// number of seconds or an HTTP date. Returns 0 if the header is absent or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
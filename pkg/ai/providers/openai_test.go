@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+func TestOpenAIProvider_ReportsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":321,"completion_tokens":12,"total_tokens":333}}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	got, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got.Text != "ok" {
+		t.Errorf("unexpected text %q", got.Text)
+	}
+	if got.Usage != (ai.Usage{PromptTokens: 321, CompletionTokens: 12}) {
+		t.Errorf("unexpected usage %+v", got.Usage)
+	}
+}
+
+func TestOpenAIProvider_SendsZeroTemperature(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	zero := 0.0
+	if _, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{Temperature: &zero}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if temperature, ok := got["temperature"]; !ok || temperature != 0.0 {
+		t.Errorf("expected an explicit zero temperature to be sent, got %v", got["temperature"])
+	}
+	if _, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, ok := got["temperature"]; ok {
+		t.Errorf("expected an unset temperature to be omitted, got %v", got["temperature"])
+	}
+}
+
+func TestOpenAIProvider_CompleteWithTools(t *testing.T) {
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"","tool_calls":[
+			{"id":"call_2","type":"function","function":{"name":"grep","arguments":"{\"pattern\":\"Close\\\\(\"}"}}]},
+			"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":50,"completion_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	messages := []ai.Message{
+		{Role: "user", Content: "review this"},
+		{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "list_dir", Arguments: "{}"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "main.go"},
+	}
+	tools := []ai.Tool{{Name: "grep", Description: "search", Parameters: map[string]any{"type": "object"}}}
+	resp, err := p.CompleteWithTools(context.Background(), messages, tools, ai.CompletionOpts{SystemPrompt: "sys"})
+	if err != nil {
+		t.Fatalf("CompleteWithTools: %v", err)
+	}
+
+	if len(got.Messages) != 4 || got.Messages[0].Role != "system" || got.Messages[3].ToolCallID != "call_1" {
+		t.Errorf("unexpected messages sent: %+v", got.Messages)
+	}
+	if call := got.Messages[2].ToolCalls; len(call) != 1 || call[0].Type != "function" || call[0].Function.Name != "list_dir" {
+		t.Errorf("expected the assistant tool call to be sent back, got %+v", call)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "grep" {
+		t.Errorf("unexpected tools sent: %+v", got.Tools)
+	}
+
+	want := []ai.ToolCall{{ID: "call_2", Name: "grep", Arguments: `{"pattern":"Close\\("}`}}
+	if !reflect.DeepEqual(resp.ToolCalls, want) {
+		t.Errorf("ToolCalls = %+v, want %+v", resp.ToolCalls, want)
+	}
+	if resp.Usage != (ai.Usage{PromptTokens: 50, CompletionTokens: 5}) {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
@@ -0,0 +1,234 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// Default retry settings used when RetryConfig fields are left at zero.
+const (
+	DefaultMaxRetries     = 3
+	DefaultInitialBackoff = 1 * time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryConfig configures a RetryProvider.
+type RetryConfig struct {
+	MaxRetries        int           // Retries after the first attempt; negative disables retrying
+	InitialBackoff    time.Duration // Delay before the first retry, doubled on each attempt
+	MaxBackoff        time.Duration // Upper bound for a single backoff delay, including Retry-After hints
+	RequestsPerMinute int           // Request budget per rolling minute (0 = unlimited)
+	TokensPerMinute   int           // Estimated token budget per rolling minute (0 = unlimited)
+}
+
+// RetryProvider wraps an ai.LLMProvider with exponential backoff, jitter,
+// Retry-After handling, and client-side request/token rate limiting.
+type RetryProvider struct {
+	next    ai.LLMProvider
+	config  RetryConfig
+	limiter *rateLimiter
+
+	// sleep waits for d or until ctx is done. Overridden in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryProvider wraps next with retry and rate-limit handling.
+func NewRetryProvider(next ai.LLMProvider, cfg RetryConfig) *RetryProvider {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	return &RetryProvider{
+		next:    next,
+		config:  cfg,
+		limiter: newRateLimiter(cfg.RequestsPerMinute, cfg.TokensPerMinute, time.Now),
+		sleep:   sleepContext,
+	}
+}
+
+// Complete forwards the request to the wrapped provider, waiting for rate-limit
+// capacity first and retrying transient failures.
+func (p *RetryProvider) Complete(ctx context.Context, prompt string, opts ai.CompletionOpts) (ai.Completion, error) {
+	tokens := ai.EstimateTokens(opts.SystemPrompt) + ai.EstimateTokens(prompt) + opts.MaxTokens
+	return withRetries(ctx, p, tokens, func() (ai.Completion, error) {
+		return p.next.Complete(ctx, prompt, opts)
+	})
+}
+
+// CompleteWithTools forwards a tool-calling request like Complete.
+func (p *RetryProvider) CompleteWithTools(ctx context.Context, messages []ai.Message, tools []ai.Tool, opts ai.CompletionOpts) (ai.ToolCompletion, error) {
+	tokens := ai.EstimateTokens(opts.SystemPrompt) + opts.MaxTokens
+	for _, m := range messages {
+		tokens += ai.EstimateTokens(m.Content)
+	}
+	return withRetries(ctx, p, tokens, func() (ai.ToolCompletion, error) {
+		return ai.CompleteWithTools(ctx, p.next, messages, tools, opts)
+	})
+}
+
+// withRetries runs call under p's rate limits, retrying transient failures.
+func withRetries[T any](ctx context.Context, p *RetryProvider, tokens int, call func() (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
+		if err := p.waitForCapacity(ctx, tokens); err != nil {
+			return zero, err
+		}
+
+		resp, err := call()
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !isRetryable(err) || attempt == p.config.MaxRetries {
+			break
+		}
+
+		delay, ok := p.backoff(attempt, err)
+		if !ok {
+			slog.Warn("ai: not retrying LLM request, Retry-After exceeds the maximum backoff",
+				"retry_after", delay, "max_backoff", p.config.MaxBackoff, "error", err)
+			break
+		}
+		slog.Warn("ai: retrying LLM request", "attempt", attempt+1, "delay", delay, "error", err)
+		if err := p.sleep(ctx, delay); err != nil {
+			return zero, err
+		}
+	}
+
+	return zero, lastErr
+}
+
+// Available delegates to the wrapped provider.
+func (p *RetryProvider) Available(ctx context.Context) bool {
+	return p.next.Available(ctx)
+}
+
+// waitForCapacity blocks until the rate limiter admits a request of the given size.
+func (p *RetryProvider) waitForCapacity(ctx context.Context, tokens int) error {
+	for {
+		wait := p.limiter.reserve(tokens)
+		if wait <= 0 {
+			return nil
+		}
+		slog.Debug("ai: waiting for rate-limit capacity", "delay", wait)
+		if err := p.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns the delay before the next retry. A Retry-After hint from the
+// provider takes precedence; otherwise the delay grows exponentially with
+// "equal jitter" (a random value between half and all of the computed delay).
+// It reports false, with the hint, when the hint is longer than MaxBackoff: the
+// provider will not accept the request sooner, so the error is returned rather
+// than stalling the scan.
+func (p *RetryProvider) backoff(attempt int, err error) (time.Duration, bool) {
+	var perr *ai.ProviderError
+	if errors.As(err, &perr) && perr.RetryAfter > 0 {
+		return perr.RetryAfter, perr.RetryAfter <= p.config.MaxBackoff
+	}
+
+	delay := p.config.InitialBackoff << attempt
+	if delay <= 0 || delay > p.config.MaxBackoff {
+		delay = p.config.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1), true
+}
+
+// isRetryable reports whether err is a transient failure worth retrying.
+func isRetryable(err error) bool {
+	var perr *ai.ProviderError
+	if errors.As(err, &perr) {
+		return perr.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter enforces request and token budgets over a rolling one-minute window.
+type rateLimiter struct {
+	mu                sync.Mutex
+	requestsPerMinute int
+	tokensPerMinute   int
+	now               func() time.Time
+	window            []rateEntry
+}
+
+type rateEntry struct {
+	at     time.Time
+	tokens int
+}
+
+func newRateLimiter(rpm, tpm int, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		requestsPerMinute: rpm,
+		tokensPerMinute:   tpm,
+		now:               now,
+	}
+}
+
+// reserve records a request of the given token size if the budget allows it and
+// returns 0. Otherwise it returns how long to wait before trying again.
+// A single request larger than the whole token budget is admitted once the
+// window is empty so that it cannot block forever.
+func (l *rateLimiter) reserve(tokens int) time.Duration {
+	if l.requestsPerMinute <= 0 && l.tokensPerMinute <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-time.Minute)
+	kept := l.window[:0]
+	used := 0
+	for _, e := range l.window {
+		if e.at.After(cutoff) {
+			kept = append(kept, e)
+			used += e.tokens
+		}
+	}
+	l.window = kept
+
+	overRequests := l.requestsPerMinute > 0 && len(l.window) >= l.requestsPerMinute
+	overTokens := l.tokensPerMinute > 0 && len(l.window) > 0 && used+tokens > l.tokensPerMinute
+	if !overRequests && !overTokens {
+		l.window = append(l.window, rateEntry{at: now, tokens: tokens})
+		return 0
+	}
+
+	// Wait until the oldest entry leaves the window, then re-check.
+	return l.window[0].at.Add(time.Minute).Sub(now)
+}
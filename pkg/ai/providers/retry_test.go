@@ -0,0 +1,217 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// scriptedProvider returns the queued errors in order, then succeeds.
+type scriptedProvider struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedProvider) Complete(_ context.Context, _ string, _ ai.CompletionOpts) (ai.Completion, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return ai.Completion{}, err
+	}
+	return ai.Completion{Text: "ok"}, nil
+}
+
+func (s *scriptedProvider) Available(_ context.Context) bool { return true }
+
+// recordSleeps replaces the provider's sleep with one that records delays.
+func recordSleeps(p *RetryProvider) *[]time.Duration {
+	var slept []time.Duration
+	p.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	return &slept
+}
+
+func TestRetryProvider_RetriesTransientErrors(t *testing.T) {
+	next := &scriptedProvider{errs: []error{
+		&ai.ProviderError{StatusCode: http.StatusTooManyRequests, Message: "429"},
+		&ai.ProviderError{StatusCode: http.StatusBadGateway, Message: "502"},
+	}}
+	p := NewRetryProvider(next, RetryConfig{InitialBackoff: 100 * time.Millisecond})
+	slept := recordSleeps(p)
+
+	got, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got.Text != "ok" {
+		t.Errorf("unexpected response: %q", got.Text)
+	}
+	if next.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", next.calls)
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("expected 2 backoff sleeps, got %d", len(*slept))
+	}
+	// Equal jitter: first delay in [50ms, 100ms], second in [100ms, 200ms].
+	if d := (*slept)[0]; d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("first backoff %v outside jitter range", d)
+	}
+	if d := (*slept)[1]; d < 100*time.Millisecond || d > 200*time.Millisecond {
+		t.Errorf("second backoff %v outside jitter range", d)
+	}
+}
+
+func TestRetryProvider_HonorsRetryAfter(t *testing.T) {
+	next := &scriptedProvider{errs: []error{
+		&ai.ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second, Message: "429"},
+	}}
+	p := NewRetryProvider(next, RetryConfig{})
+	slept := recordSleeps(p)
+
+	if _, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 7*time.Second {
+		t.Errorf("expected a single 7s Retry-After sleep, got %v", *slept)
+	}
+}
+
+func TestRetryProvider_GivesUpOnLongRetryAfter(t *testing.T) {
+	next := &scriptedProvider{errs: []error{
+		&ai.ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: 24 * time.Hour, Message: "429"},
+	}}
+	p := NewRetryProvider(next, RetryConfig{MaxBackoff: time.Minute})
+	slept := recordSleeps(p)
+
+	_, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+	var perr *ai.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the 429 error to be returned, got %v", err)
+	}
+	if next.calls != 1 || len(*slept) != 0 {
+		t.Errorf("expected no retry past the maximum backoff, got %d calls and sleeps %v", next.calls, *slept)
+	}
+}
+
+func TestRetryProvider_DoesNotRetryPermanentErrors(t *testing.T) {
+	next := &scriptedProvider{errs: []error{
+		&ai.ProviderError{StatusCode: http.StatusUnauthorized, Message: "401"},
+	}}
+	p := NewRetryProvider(next, RetryConfig{})
+	recordSleeps(p)
+
+	_, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{})
+	var perr *ai.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the 401 error to be returned, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected no retries for 401, got %d calls", next.calls)
+	}
+}
+
+func TestRetryProvider_GivesUpAfterMaxRetries(t *testing.T) {
+	var errs []error
+	for i := 0; i < 10; i++ {
+		errs = append(errs, &ai.ProviderError{StatusCode: http.StatusServiceUnavailable, Message: "503"})
+	}
+	next := &scriptedProvider{errs: errs}
+	p := NewRetryProvider(next, RetryConfig{MaxRetries: 2})
+	recordSleeps(p)
+
+	if _, err := p.Complete(context.Background(), "prompt", ai.CompletionOpts{}); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if next.calls != 3 {
+		t.Errorf("expected 3 calls (1 + 2 retries), got %d", next.calls)
+	}
+}
+
+func TestRetryProvider_StopsOnCancelledContext(t *testing.T) {
+	next := &scriptedProvider{errs: []error{
+		&ai.ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Second, Message: "429"},
+	}}
+	p := NewRetryProvider(next, RetryConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := p.Complete(ctx, "prompt", ai.CompletionOpts{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline error, got %v", err)
+	}
+}
+
+func TestRateLimiter_RequestsPerMinute(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, 0, func() time.Time { return now })
+
+	if w := l.reserve(10); w != 0 {
+		t.Fatalf("first request should be admitted, got wait %v", w)
+	}
+	now = now.Add(10 * time.Second)
+	if w := l.reserve(10); w != 0 {
+		t.Fatalf("second request should be admitted, got wait %v", w)
+	}
+	now = now.Add(10 * time.Second)
+	if w := l.reserve(10); w != 40*time.Second {
+		t.Errorf("third request should wait 40s for the window, got %v", w)
+	}
+
+	now = now.Add(41 * time.Second)
+	if w := l.reserve(10); w != 0 {
+		t.Errorf("request should be admitted once the oldest entry expires, got wait %v", w)
+	}
+}
+
+func TestRateLimiter_TokensPerMinute(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(0, 1000, func() time.Time { return now })
+
+	if w := l.reserve(800); w != 0 {
+		t.Fatalf("first request should be admitted, got wait %v", w)
+	}
+	if w := l.reserve(300); w == 0 {
+		t.Error("expected second request to exceed the token budget")
+	}
+	if w := l.reserve(200); w != 0 {
+		t.Errorf("small request should fit the remaining budget, got wait %v", w)
+	}
+}
+
+func TestRateLimiter_OversizedRequestAdmittedWhenIdle(t *testing.T) {
+	l := newRateLimiter(0, 100, time.Now)
+	if w := l.reserve(5000); w != 0 {
+		t.Errorf("oversized request should be admitted on an empty window, got wait %v", w)
+	}
+}
+
+func TestOpenAIProvider_RateLimitCarriesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 0)
+	_, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+
+	var perr *ai.ProviderError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ai.ProviderError, got %T: %v", err, err)
+	}
+	if perr.RetryAfter != 3*time.Second {
+		t.Errorf("expected RetryAfter 3s, got %v", perr.RetryAfter)
+	}
+	if !perr.Retryable() {
+		t.Error("expected 429 to be retryable")
+	}
+}
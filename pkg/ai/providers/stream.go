@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// DefaultStreamIdleTimeout is how long a streamed response may go without
+// data when no idle timeout is given.
+const DefaultStreamIdleTimeout = 60 * time.Second
+
+// maxStreamLine bounds a single server-sent event line.
+const maxStreamLine = 4 << 20
+
+// NewStreamingOpenAIProvider creates a provider for OpenAI-compatible
+// endpoints that streams responses as server-sent events. There is no total
+// request timeout: a request fails only when the endpoint sends nothing for
+// idleTimeout, so slow models that are still producing tokens are not cut
+// off. The idle timeout also bounds the wait for the first chunk.
+func NewStreamingOpenAIProvider(cfg ai.ProviderConfig, idleTimeout time.Duration) *OpenAIProvider {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultStreamIdleTimeout
+	}
+	return &OpenAIProvider{
+		config:      cfg,
+		client:      &http.Client{},
+		idleTimeout: idleTimeout,
+	}
+}
+
+// streamOptions configures a streamed chat completion.
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// streamIdleError is returned when a stream stalls for longer than the idle
+// timeout. It is a net.Error reporting a timeout, so RetryProvider retries it.
+type streamIdleError struct {
+	url  string
+	idle time.Duration
+}
+
+func (e *streamIdleError) Error() string {
+	return fmt.Sprintf("ai: no data from %s for %s", e.url, e.idle)
+}
+
+// Timeout implements net.Error.
+func (e *streamIdleError) Timeout() bool { return true }
+
+// Temporary implements net.Error.
+func (e *streamIdleError) Temporary() bool { return true }
+
+// chatStream sends a streaming chat completions request and assembles the
+// response from its chunks as they arrive.
+func (p *OpenAIProvider) chatStream(ctx context.Context, url string, body []byte) (ai.ToolCompletion, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	idle := &streamIdleError{url: url, idle: p.idleTimeout}
+	timer := time.AfterFunc(p.idleTimeout, func() { cancel(idle) })
+	defer timer.Stop()
+
+	// failed prefers the idle timeout over the cancellation error it causes.
+	failed := func(format string, err error) error {
+		if errors.Is(context.Cause(ctx), idle) {
+			return idle
+		}
+		return fmt.Errorf(format, err)
+	}
+
+	req, err := newPostRequest(ctx, url, p.config.APIKey, body)
+	if err != nil {
+		return ai.ToolCompletion{}, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ai.ToolCompletion{}, failed("ai: sending request to "+url+": %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return ai.ToolCompletion{}, failed("ai: reading response: %w", err)
+		}
+		return ai.ToolCompletion{}, statusError(resp, respBody)
+	}
+
+	var acc streamAccumulator
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		timer.Reset(p.idleTimeout)
+		// Only data lines matter; event names, comments (keep-alives) and
+		// the blank lines between events are skipped.
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		if err := acc.add([]byte(data)); err != nil {
+			return ai.ToolCompletion{}, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ai.ToolCompletion{}, failed("ai: reading stream: %w", err)
+	}
+	if !acc.started {
+		return ai.ToolCompletion{}, fmt.Errorf("ai: provider returned no choices")
+	}
+	return acc.completion(), nil
+}
+
+// streamChunk is one chat.completion.chunk event.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// streamAccumulator assembles a completion from stream chunks. Text and tool
+// call arguments arrive in fragments; tool calls are identified by index.
+type streamAccumulator struct {
+	text    strings.Builder
+	calls   []ai.ToolCall
+	usage   ai.Usage
+	started bool // a chunk with a choice has been seen
+}
+
+// add applies one chunk's data.
+func (a *streamAccumulator) add(data []byte) error {
+	var chunk streamChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return fmt.Errorf("ai: decoding stream chunk: %w", err)
+	}
+	if chunk.Error != nil {
+		return fmt.Errorf("ai: provider error: %s", chunk.Error.Message)
+	}
+	if chunk.Usage != nil {
+		a.usage = ai.Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+	}
+	if len(chunk.Choices) == 0 {
+		return nil // e.g. the final usage-only chunk
+	}
+	a.started = true
+
+	delta := chunk.Choices[0].Delta
+	a.text.WriteString(delta.Content)
+	for _, tc := range delta.ToolCalls {
+		if tc.Index < 0 || tc.Index > len(a.calls)+64 {
+			return fmt.Errorf("ai: stream chunk has tool call index %d", tc.Index)
+		}
+		for len(a.calls) <= tc.Index {
+			a.calls = append(a.calls, ai.ToolCall{})
+		}
+		call := &a.calls[tc.Index]
+		if tc.ID != "" {
+			call.ID = tc.ID
+		}
+		if tc.Function.Name != "" {
+			call.Name = tc.Function.Name
+		}
+		call.Arguments += tc.Function.Arguments
+	}
+	return nil
+}
+
+// completion returns the assembled completion.
+func (a *streamAccumulator) completion() ai.ToolCompletion {
+	return ai.ToolCompletion{
+		Completion: ai.Completion{Text: a.text.String(), Usage: a.usage},
+		ToolCalls:  a.calls,
+	}
+}
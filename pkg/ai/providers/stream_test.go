@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+)
+
+// sseServer streams events, waiting delay before each one, then holds the
+// connection open for hold before ending the stream.
+func sseServer(t *testing.T, delay, hold time.Duration, events ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("expected a streaming request with usage, got %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, event := range events {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(delay):
+			}
+			_, _ = fmt.Fprintf(w, "%s\n\n", event)
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(hold):
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIProvider_StreamAssemblesChunks(t *testing.T) {
+	server := sseServer(t, 0, 0,
+		": keep-alive",
+		`data: {"choices":[{"delta":{"role":"assistant","content":"{\"findings\""}}]}`,
+		`data: {"choices":[{"delta":{"content":": []}"}}]}`,
+		`data: {"choices":[{"delta":{},"finish_reason":"stop"}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":120,"completion_tokens":7}}`,
+		`data: [DONE]`,
+	)
+
+	p := NewStreamingOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, time.Second)
+	got, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got.Text != `{"findings": []}` {
+		t.Errorf("unexpected text %q", got.Text)
+	}
+	if got.Usage != (ai.Usage{PromptTokens: 120, CompletionTokens: 7}) {
+		t.Errorf("unexpected usage %+v", got.Usage)
+	}
+}
+
+func TestOpenAIProvider_StreamAssemblesToolCalls(t *testing.T) {
+	server := sseServer(t, 0, 0,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"read_file","arguments":""}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"list_dir","arguments":"{}"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":" \"main.go\"}"}}]}}]}`,
+		`data: [DONE]`,
+	)
+
+	p := NewStreamingOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, time.Second)
+	resp, err := p.CompleteWithTools(context.Background(), []ai.Message{{Role: "user", Content: "review"}}, nil, ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("CompleteWithTools: %v", err)
+	}
+	want := []ai.ToolCall{
+		{ID: "call_1", Name: "read_file", Arguments: `{"path": "main.go"}`},
+		{ID: "call_2", Name: "list_dir", Arguments: "{}"},
+	}
+	if !reflect.DeepEqual(resp.ToolCalls, want) {
+		t.Errorf("ToolCalls = %+v, want %+v", resp.ToolCalls, want)
+	}
+}
+
+func TestOpenAIProvider_StreamIdleTimeout(t *testing.T) {
+	server := sseServer(t, 0, 5*time.Second,
+		`data: {"choices":[{"delta":{"content":"partial"}}]}`,
+	)
+
+	p := NewStreamingOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 100*time.Millisecond)
+	start := time.Now()
+	_, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+	if err == nil {
+		t.Fatal("expected the stalled stream to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the idle timeout to end the request, took %s", elapsed)
+	}
+	if !isRetryable(err) {
+		t.Errorf("expected an idle timeout to be retryable, got %v", err)
+	}
+}
+
+func TestOpenAIProvider_StreamOutlivesIdleTimeout(t *testing.T) {
+	// Five chunks 60ms apart take longer than the 150ms idle timeout in total,
+	// but the stream never goes quiet for that long.
+	server := sseServer(t, 60*time.Millisecond, 0,
+		`data: {"choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"choices":[{"delta":{"content":"b"}}]}`,
+		`data: {"choices":[{"delta":{"content":"c"}}]}`,
+		`data: {"choices":[{"delta":{"content":"d"}}]}`,
+		`data: {"choices":[{"delta":{"content":"e"}}]}`,
+		`data: [DONE]`,
+	)
+
+	p := NewStreamingOpenAIProvider(ai.ProviderConfig{Endpoint: server.URL, Model: "m"}, 150*time.Millisecond)
+	got, err := p.Complete(context.Background(), "hi", ai.CompletionOpts{})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got.Text != "abcde" {
+		t.Errorf("unexpected text %q", got.Text)
+	}
+}
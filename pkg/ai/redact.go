@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/analyzer"
+)
+
+// redactingProvider removes secrets from every prompt before it reaches the
+// wrapped provider, using the same rules as the secrets analyzer. Redacted
+// values are recorded in the redactionStats carried by the request context.
+type redactingProvider struct {
+	next LLMProvider
+}
+
+// Complete redacts the prompt and forwards the request.
+func (p redactingProvider) Complete(ctx context.Context, prompt string, opts CompletionOpts) (Completion, error) {
+	redacted, values := analyzer.RedactSecrets(prompt)
+	if stats, ok := ctx.Value(redactionStatsKey{}).(*redactionStats); ok {
+		stats.add(values)
+	}
+	return p.next.Complete(ctx, redacted, opts)
+}
+
+// CompleteWithTools redacts every message, including tool results, and
+// forwards the request.
+func (p redactingProvider) CompleteWithTools(ctx context.Context, messages []Message, tools []Tool, opts CompletionOpts) (ToolCompletion, error) {
+	stats, _ := ctx.Value(redactionStatsKey{}).(*redactionStats)
+	redacted := make([]Message, len(messages))
+	for i, m := range messages {
+		content, values := analyzer.RedactSecrets(m.Content)
+		if stats != nil {
+			stats.add(values)
+		}
+		m.Content = content
+		redacted[i] = m
+	}
+	return CompleteWithTools(ctx, p.next, redacted, tools, opts)
+}
+
+// Available reports whether the wrapped provider is reachable.
+func (p redactingProvider) Available(ctx context.Context) bool {
+	return p.next.Available(ctx)
+}
+
+// redactionStatsKey is the context key for a review's redactionStats.
+type redactionStatsKey struct{}
+
+// redactionStats collects the distinct values redacted during one review.
+// The same secret usually appears in several prompts (one per pass), so
+// values are counted once.
+type redactionStats struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+// withRedactionStats returns a context that records redactions in a new
+// redactionStats, and that stats value.
+func withRedactionStats(ctx context.Context) (context.Context, *redactionStats) {
+	stats := &redactionStats{values: make(map[string]bool)}
+	return context.WithValue(ctx, redactionStatsKey{}, stats), stats
+}
+
+func (s *redactionStats) add(values []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range values {
+		s.values[v] = true
+	}
+}
+
+// count returns the number of distinct values redacted so far.
+func (s *redactionStats) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.values)
+}
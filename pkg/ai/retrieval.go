@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/analyzer"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// DefaultRetrievalTopK is the number of related chunks retrieved per hunk
+// when WithRetrieval is given no positive limit.
+const DefaultRetrievalTopK = 3
+
+// retrievalQueryChars bounds the hunk text embedded as a retrieval query.
+const retrievalQueryChars = 4000
+
+// WithRetrieval adds repository code related to the change to each review
+// chunk: every changed hunk is embedded and the topK most similar index
+// chunks are retrieved, typically the callers and definitions of the code
+// being changed. Related code may use up to a quarter of the context budget;
+// the best matches are kept when it does not all fit. If embedding the hunks
+// fails, the review runs without related code.
+func WithRetrieval(index *Index, embedder Embedder, topK int) Option {
+	return func(r *Reviewer) {
+		if topK <= 0 {
+			topK = DefaultRetrievalTopK
+		}
+		r.index = index
+		r.embedder = embedder
+		r.retrievalTopK = topK
+	}
+}
+
+// retrievalBudget is the share of budget reserved for related code.
+func (r *Reviewer) retrievalBudget(budget int) int {
+	if r.index == nil || r.embedder == nil || len(r.index.Chunks) == 0 {
+		return 0
+	}
+	return budget / 4
+}
+
+// hunkQuery is a changed hunk to retrieve related code for.
+type hunkQuery struct {
+	chunk      int // index of the review chunk the hunk's file is in
+	path       string
+	start, end int // new-file line range of the hunk
+}
+
+// relatedContext retrieves related code for the hunks in each chunk of the
+// plan and returns one context section per chunk, each within maxTokens,
+// and the number of distinct index chunks used.
+func (r *Reviewer) relatedContext(ctx context.Context, diff *interfaces.Diff, plan ChunkPlan, maxTokens int) ([]string, int) {
+	sections := make([]string, len(plan.Chunks))
+	if maxTokens <= 0 {
+		return sections, 0
+	}
+
+	files := make(map[string]interfaces.FileDiff, len(diff.Files))
+	for _, f := range diff.Files {
+		files[f.Path] = f
+	}
+	var queries []hunkQuery
+	var texts []string
+	for i, chunk := range plan.Chunks {
+		for _, path := range chunk.Files {
+			for _, h := range files[path].Hunks {
+				text, _ := analyzer.RedactSecrets(truncateStr(h.Content, retrievalQueryChars))
+				queries = append(queries, hunkQuery{chunk: i, path: path, start: h.NewStart, end: h.NewStart + max(h.NewLines, 1) - 1})
+				texts = append(texts, embeddingText(path, text))
+			}
+		}
+	}
+	if len(queries) == 0 {
+		return sections, 0
+	}
+
+	vectors, err := embedAll(ctx, r.embedder, texts)
+	if err != nil {
+		slog.Warn("AI review: retrieving related code failed, continuing without it", "error", err)
+		return sections, 0
+	}
+
+	// Merge each chunk's hits, keeping a related chunk's best score.
+	best := make([]map[*IndexChunk]float64, len(plan.Chunks))
+	for qi, q := range queries {
+		if best[q.chunk] == nil {
+			best[q.chunk] = make(map[*IndexChunk]float64)
+		}
+		// The changed code itself is already in the diff.
+		skip := func(c *IndexChunk) bool {
+			return c.Path == q.path && c.StartLine <= q.end && c.EndLine >= q.start
+		}
+		for _, hit := range r.index.Search(vectors[qi], r.retrievalTopK, skip) {
+			if score, ok := best[q.chunk][hit.Chunk]; !ok || hit.Score > score {
+				best[q.chunk][hit.Chunk] = hit.Score
+			}
+		}
+	}
+
+	used := make(map[*IndexChunk]bool)
+	for i, scores := range best {
+		hits := make([]IndexHit, 0, len(scores))
+		for c, score := range scores {
+			hits = append(hits, IndexHit{Chunk: c, Score: score})
+		}
+		sort.Slice(hits, func(a, b int) bool {
+			if hits[a].Score != hits[b].Score {
+				return hits[a].Score > hits[b].Score
+			}
+			if hits[a].Chunk.Path != hits[b].Chunk.Path {
+				return hits[a].Chunk.Path < hits[b].Chunk.Path
+			}
+			return hits[a].Chunk.StartLine < hits[b].Chunk.StartLine
+		})
+		sections[i] = r.relatedSection(hits, maxTokens, used)
+	}
+	return sections, len(used)
+}
+
+// relatedSection formats hits, best first, while they fit within maxTokens,
+// and marks those included in used.
+func (r *Reviewer) relatedSection(hits []IndexHit, maxTokens int, used map[*IndexChunk]bool) string {
+	const header = "Related repository code (retrieved by similarity to the changed hunks, as last indexed; not part of this change):\n"
+	var b strings.Builder
+	remaining := maxTokens - r.counter.CountTokens(header)
+	for _, hit := range hits {
+		c := hit.Chunk
+		section := fmt.Sprintf("--- Related: %s:%d-%d ---\n%s\n", c.Path, c.StartLine, c.EndLine, c.Text)
+		n := r.counter.CountTokens(section)
+		if n > remaining {
+			continue // A smaller, less similar chunk may still fit.
+		}
+		remaining -= n
+		b.WriteString(section)
+		used[c] = true
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return header + b.String() + "\n"
+}
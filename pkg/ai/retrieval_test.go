@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// ledgerDiff changes main.go to call the indexed OpenLedger function.
+func ledgerDiff() *interfaces.Diff {
+	return &interfaces.Diff{Files: []interfaces.FileDiff{{
+		Path:     "main.go",
+		Status:   interfaces.FileModified,
+		Language: "go",
+		Hunks: []interfaces.Hunk{{
+			NewStart:   4,
+			NewLines:   1,
+			Content:    "+\tledger, _ := OpenLedger(dsn)",
+			AddedLines: []interfaces.Line{{Number: 4, Content: "\tledger, _ := OpenLedger(dsn)"}},
+		}},
+	}}}
+}
+
+func TestReviewer_Review_RetrievesRelatedCode(t *testing.T) {
+	index, _, err := BuildIndex(context.Background(), indexRepo(t), &wordEmbedder{}, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+
+	var mu sync.Mutex
+	var prompts []string
+	provider := &funcProvider{complete: func(prompt string, _ CompletionOpts) string {
+		mu.Lock()
+		defer mu.Unlock()
+		prompts = append(prompts, prompt)
+		return `{"findings": []}`
+	}}
+	result, err := NewReviewer(provider, WithRetrieval(index, &wordEmbedder{}, 1)).Review(context.Background(), ledgerDiff(), nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	for _, prompt := range prompts {
+		if !strings.Contains(prompt, "--- Related: pkg/store/store.go:1-6 ---") || !strings.Contains(prompt, "return connectLedger(dsn)") {
+			t.Errorf("expected the called function in the context, got %q", prompt)
+		}
+		if strings.Contains(prompt, "Related: main.go") {
+			t.Error("expected the changed code itself not to be retrieved")
+		}
+	}
+	if result.Metadata["related_chunks"] != 1 {
+		t.Errorf("expected 1 related chunk recorded, got %v", result.Metadata["related_chunks"])
+	}
+}
+
+func TestReviewer_Review_RetrievalStaysWithinBudget(t *testing.T) {
+	index, _, err := BuildIndex(context.Background(), indexRepo(t), &wordEmbedder{}, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	provider := &mockProvider{available: true, responses: []string{`{"findings": []}`, `{"findings": []}`, `{"findings": []}`}}
+
+	// A quarter of 200 tokens leaves no room for the related chunk.
+	result, err := NewReviewer(provider, WithMaxTokenBudget(200), WithRetrieval(index, &wordEmbedder{}, 3)).Review(context.Background(), ledgerDiff(), nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if result.Metadata["related_chunks"] != 0 {
+		t.Errorf("expected related code left out when it does not fit, got %v", result.Metadata["related_chunks"])
+	}
+}
+
+func TestReviewer_Review_RetrievalFailureIsNotFatal(t *testing.T) {
+	index, _, err := BuildIndex(context.Background(), indexRepo(t), &wordEmbedder{}, "words", nil)
+	if err != nil {
+		t.Fatalf("BuildIndex: %v", err)
+	}
+	provider := &mockProvider{available: true, responses: []string{`{"findings": []}`, `{"findings": []}`, `{"findings": []}`}}
+
+	down := &wordEmbedder{err: errors.New("connection refused")}
+	result, err := NewReviewer(provider, WithRetrieval(index, down, 0)).Review(context.Background(), ledgerDiff(), nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if provider.callIndex != 3 || result.Metadata["related_chunks"] != 0 {
+		t.Errorf("expected the review to run without related code, got %d calls, %v related", provider.callIndex, result.Metadata["related_chunks"])
+	}
+}
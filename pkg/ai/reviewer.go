@@ -3,9 +3,11 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
@@ -16,6 +18,24 @@ import (
 type Reviewer struct {
 	provider       LLMProvider
 	maxTokenBudget int
+	counter        TokenCounter
+	maxChunks      int
+	concurrency    int
+	repoRoot       string
+	customPasses   []PassConfig
+	disabledPasses map[string]bool
+	fixPatches     bool
+	walkthrough    bool
+	lineTolerance  int
+	consensus      []ModelProvider
+	minAgreement   int
+	agentTurns     int                     // tool rounds per agentic pass; 0 disables tools
+	agentPasses    map[string]bool         // passes that may call tools; nil means all
+	languagePacks  map[string]LanguagePack // overrides of the built-in packs, by language
+	index          *Index                  // repository index for retrieving related code; nil disables
+	embedder       Embedder                // embeds hunks to query index
+	retrievalTopK  int                     // related chunks retrieved per hunk
+	noToolsOnce    sync.Once
 }
 
 // Option configures a Reviewer.
@@ -28,85 +48,237 @@ func WithMaxTokenBudget(budget int) Option {
 	}
 }
 
+// WithTokenCounter sets how context is measured against the token budget.
+// Defaults to HeuristicCounter.
+func WithTokenCounter(c TokenCounter) Option {
+	return func(r *Reviewer) {
+		r.counter = c
+	}
+}
+
+// WithMaxChunks limits how many budget-sized context chunks a review may use.
+// Files that do not fit are skipped and reported in the result metadata.
+func WithMaxChunks(n int) Option {
+	return func(r *Reviewer) {
+		r.maxChunks = n
+	}
+}
+
+// WithRepoRoot sets the repository checkout used to resolve
+// AIReviewOptions.ContextFiles. Defaults to the current directory.
+func WithRepoRoot(root string) Option {
+	return func(r *Reviewer) {
+		r.repoRoot = root
+	}
+}
+
+// WithCustomPasses adds user-defined review passes that run after the built-in ones.
+func WithCustomPasses(passes ...PassConfig) Option {
+	return func(r *Reviewer) {
+		r.customPasses = append(r.customPasses, passes...)
+	}
+}
+
+// WithDisabledPasses turns off built-in passes by name ("semantic", "logic", "convention").
+func WithDisabledPasses(names ...string) Option {
+	return func(r *Reviewer) {
+		if r.disabledPasses == nil {
+			r.disabledPasses = make(map[string]bool)
+		}
+		for _, n := range names {
+			r.disabledPasses[strings.ToLower(strings.TrimSpace(n))] = true
+		}
+	}
+}
+
+// WithFixPatches makes the reviewer ask the model for a unified-diff fix for
+// every high and critical finding. Only patches that apply cleanly to the
+// finding's hunk are kept.
+func WithFixPatches(enabled bool) Option {
+	return func(r *Reviewer) {
+		r.fixPatches = enabled
+	}
+}
+
+// WithWalkthrough makes the reviewer ask the model for an overview of the
+// change: a summary, one line per file and the riskiest areas. It is
+// returned in the result metadata under "walkthrough".
+func WithWalkthrough(enabled bool) Option {
+	return func(r *Reviewer) {
+		r.walkthrough = enabled
+	}
+}
+
+// WithLineTolerance sets how many lines a finding may be from an added line
+// and still be moved onto it rather than discarded (default
+// DefaultLineTolerance; 0 accepts only findings on added lines).
+func WithLineTolerance(n int) Option {
+	return func(r *Reviewer) {
+		r.lineTolerance = n
+	}
+}
+
+// WithConcurrency sets how many review passes may run in parallel.
+// Values below 1 are treated as 1 (sequential).
+func WithConcurrency(n int) Option {
+	return func(r *Reviewer) {
+		r.concurrency = n
+	}
+}
+
 // NewReviewer creates an AI reviewer backed by the given LLM provider.
 func NewReviewer(provider LLMProvider, opts ...Option) *Reviewer {
 	r := &Reviewer{
 		provider:       provider,
 		maxTokenBudget: DefaultMaxTokenBudget,
+		counter:        HeuristicCounter{},
+		maxChunks:      DefaultMaxChunks,
+		concurrency:    1,
+		repoRoot:       ".",
+		lineTolerance:  DefaultLineTolerance,
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
+	if r.concurrency < 1 {
+		r.concurrency = 1
+	}
+	if r.counter == nil {
+		r.counter = HeuristicCounter{}
+	}
+
+	// Secrets never leave the process, whichever provider is configured.
+	r.provider = redactingProvider{next: r.provider}
+	for i := range r.consensus {
+		r.consensus[i].Provider = redactingProvider{next: r.consensus[i].Provider}
+	}
 	return r
 }
 
-// Review performs AI-powered analysis of the diff using three review passes:
-// semantic, logic, and convention. Reviews run sequentially to respect rate limits.
+// reviewPass describes a single LLM review pass over one context chunk.
+type reviewPass struct {
+	name         string // pass name, including the chunk label when chunked
+	passName     string // pass name as configured, recorded on findings
+	category     interfaces.Category
+	systemPrompt string
+	userPrompt   string
+	maxTokens    int
+	temperature  *float64
+	model        string      // consensus model name; empty for a single-model review
+	provider     LLMProvider // provider the pass is sent to
+	budget       int         // context token budget the user prompt counts against
+}
+
+// Review performs AI-powered analysis of the diff using three built-in review
+// passes (semantic, logic, and convention) plus any custom passes. Diffs larger than the token budget are split
+// into file-grouped chunks and every pass runs over every chunk. Passes run
+// sequentially by default to respect rate limits; WithConcurrency allows several
+// to run at once. Findings are always merged in pass order, regardless of
+// completion order. With WithConsensus each pass is sent to every model and
+// only findings enough models agree on are kept. Diff content is fenced as
+// untrusted in every prompt, and text aimed at manipulating the reviewer is
+// reported as security findings. Secrets are redacted from every prompt before
+// it is sent. With WithAgenticTools, passes may read more of the repository
+// through tools before answering. Each pass's instructions are extended with
+// the language packs for the languages in its chunk. With WithRetrieval, code
+// related to the changed hunks is added to each chunk's context.
 func (r *Reviewer) Review(ctx context.Context, diff *interfaces.Diff, opts *interfaces.AIReviewOptions) (*interfaces.AnalysisResult, error) {
 	start := time.Now()
+	ctx, redactions := withRedactionStats(ctx)
 
 	budget := r.maxTokenBudget
 	if opts != nil && opts.MaxTokens > 0 {
 		budget = opts.MaxTokens
 	}
-	diffContext := BuildContext(diff, budget)
 
-	var allFindings []interfaces.Finding
+	// Project context (focus areas and reference files) is shared by every
+	// chunk, so it may use at most a third of the budget.
+	var projectContext string
+	var contextFiles []string
+	if opts != nil {
+		projectContext, contextFiles = BuildProjectContext(r.repoRoot, opts.ContextFiles, opts.FocusAreas, r.counter, budget/3)
+	}
+	relatedBudget := r.retrievalBudget(budget)
+	plan := BuildContextChunks(diff, r.counter, budget-r.counter.CountTokens(projectContext)-relatedBudget, r.maxChunks)
+	if len(plan.Skipped) > 0 {
+		slog.Warn("AI review: some files did not fit the review budget", "skipped", len(plan.Skipped))
+	}
+	related, relatedChunks := r.relatedContext(ctx, diff, plan, relatedBudget)
 
-	// Run review passes sequentially to respect rate limits.
-	type reviewPass struct {
-		name         string
-		category     interfaces.Category
-		systemPrompt string
-		userPrompt   string
-	}
-
-	passes := []reviewPass{
-		{
-			name:         "semantic",
-			category:     interfaces.CategoryLogic,
-			systemPrompt: prompts.SemanticSystemPrompt(),
-			userPrompt:   prompts.SemanticPrompt(diffContext),
-		},
-		{
-			name:         "logic",
-			category:     interfaces.CategoryLogic,
-			systemPrompt: prompts.LogicSystemPrompt(),
-			userPrompt:   prompts.LogicPrompt(diffContext),
-		},
-		{
-			name:         "convention",
-			category:     interfaces.CategoryConvention,
-			systemPrompt: prompts.ConventionSystemPrompt(),
-			userPrompt:   prompts.ConventionPrompt(diffContext),
-		},
-	}
-
-	for _, pass := range passes {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+	models := r.consensus
+	if len(models) == 0 {
+		models = []ModelProvider{{Provider: r.provider}}
+	}
+
+	var passes []reviewPass
+	for _, def := range r.passDefinitions() {
+		for i, chunk := range plan.Chunks {
+			name := def.name
+			if len(plan.Chunks) > 1 {
+				name = fmt.Sprintf("%s[%d/%d]", def.name, i+1, len(plan.Chunks))
+			}
+			userPrompt := def.userPrompt(prompts.FenceUntrusted(projectContext + related[i] + chunk.Text))
+			for _, m := range models {
+				passName := name
+				if m.Name != "" {
+					passName = name + "@" + m.Name
+				}
+				passes = append(passes, reviewPass{
+					name:         passName,
+					passName:     def.name,
+					category:     def.category,
+					systemPrompt: prompts.HardenSystemPrompt(def.systemPrompt + r.languageSection(def.name, chunk.Languages)),
+					userPrompt:   userPrompt,
+					maxTokens:    def.maxTokens,
+					temperature:  def.temperature,
+					model:        m.Name,
+					provider:     m.Provider,
+					budget:       budget,
+				})
+			}
 		}
+	}
 
-		slog.Info("running AI review pass", "pass", pass.name)
+	outcomes, err := r.runPasses(ctx, passes)
+	if err != nil {
+		return nil, err
+	}
 
-		response, err := r.provider.Complete(ctx, pass.userPrompt, CompletionOpts{
-			MaxTokens:    2048,
-			Temperature:  0.1,
-			SystemPrompt: pass.systemPrompt,
-		})
-		if err != nil {
-			slog.Warn("AI review pass failed", "pass", pass.name, "error", err)
-			continue
+	var allFindings []interfaces.Finding
+	var total Usage
+	passUsage := make(map[string]interfaces.TokenUsage)
+	addUsage := func(pass string, u Usage) {
+		total = total.Add(u)
+		pu := passUsage[pass]
+		pu.PromptTokens += u.PromptTokens
+		pu.CompletionTokens += u.CompletionTokens
+		passUsage[pass] = pu
+	}
+	modelUsage := make(map[string]interfaces.TokenUsage)
+	toolCalls := 0
+	for i, o := range outcomes {
+		allFindings = append(allFindings, o.findings...)
+		toolCalls += o.toolCalls
+		addUsage(passes[i].passName, o.usage)
+		if m := passes[i].model; m != "" {
+			mu := modelUsage[m]
+			mu.PromptTokens += o.usage.PromptTokens
+			mu.CompletionTokens += o.usage.CompletionTokens
+			modelUsage[m] = mu
 		}
+	}
 
-		findings, confidence := parseFindings(response, pass.category)
-		if confidence < 0.3 {
-			slog.Warn("AI response poorly structured, skipping findings", "pass", pass.name, "confidence", confidence)
-			continue
-		}
+	allFindings, guard := validateFindings(diff, allFindings, r.lineTolerance)
+	if guard.discarded > 0 || guard.adjusted > 0 {
+		slog.Info("validated AI finding locations", "discarded", guard.discarded, "adjusted", guard.adjusted)
+	}
 
-		slog.Info("AI review pass complete", "pass", pass.name, "findings", len(findings), "confidence", confidence)
-		allFindings = append(allFindings, findings...)
+	rejected := 0
+	if len(r.consensus) > 0 {
+		before := len(allFindings)
+		allFindings, rejected = applyConsensus(allFindings, len(r.consensus), r.consensusAgreement())
+		slog.Info("applied AI model consensus", "models", len(r.consensus), "min_agreement", r.consensusAgreement(),
+			"before", before, "after", len(allFindings), "rejected", rejected)
 	}
 
 	deduped := deduplicateFindings(allFindings)
@@ -114,15 +286,184 @@ func (r *Reviewer) Review(ctx context.Context, diff *interfaces.Diff, opts *inte
 		slog.Info("deduplicated AI findings", "before", len(allFindings), "after", len(deduped), "removed", removed)
 	}
 
+	var walkthrough *interfaces.Walkthrough
+	if r.walkthrough && len(plan.Chunks) > 0 {
+		w, u, err := r.writeWalkthrough(ctx, diff, projectContext+plan.Chunks[0].Text)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			slog.Warn("AI walkthrough failed", "error", err)
+		}
+		walkthrough = w
+		addUsage("walkthrough", u)
+	}
+
+	fixes := 0
+	if r.fixPatches {
+		var fixUsage Usage
+		fixes, fixUsage = r.attachFixes(ctx, diff, deduped)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		addUsage("fix", fixUsage)
+	}
+
+	// Injection attempts are found statically, so they are reported even when
+	// they succeed in steering the model.
+	injections := DetectPromptInjection(diff)
+	if len(injections) > 0 {
+		slog.Warn("possible prompt injection in diff", "findings", len(injections))
+		deduped = append(deduped, injections...)
+	}
+
+	if n := redactions.count(); n > 0 {
+		slog.Info("redacted secrets from AI review prompts", "values", n)
+	}
+
+	metadata := map[string]any{
+		"chunks":             len(plan.Chunks),
+		"reviewed_files":     plan.ReviewedFiles(),
+		"skipped_files":      plan.Skipped,
+		"context_files":      contextFiles,
+		"fix_patches":        fixes,
+		"discarded_findings": guard.discarded,
+		"adjusted_findings":  guard.adjusted,
+		"injection_findings": len(injections),
+		"redacted_secrets":   redactions.count(),
+		"prompt_tokens":      total.PromptTokens,
+		"completion_tokens":  total.CompletionTokens,
+		"pass_usage":         passUsage,
+	}
+	if walkthrough != nil {
+		metadata["walkthrough"] = walkthrough
+	}
+	if r.agentTurns > 0 {
+		metadata["tool_calls"] = toolCalls
+	}
+	if relatedBudget > 0 {
+		metadata["related_chunks"] = relatedChunks
+	}
+	if len(r.consensus) > 0 {
+		names := make([]string, len(r.consensus))
+		for i, m := range r.consensus {
+			names[i] = m.Name
+		}
+		metadata["consensus_models"] = names
+		metadata["consensus_min_agreement"] = r.consensusAgreement()
+		metadata["consensus_rejected"] = rejected
+		metadata["model_usage"] = modelUsage
+	}
+
 	return &interfaces.AnalysisResult{
 		AnalyzerName: "ai-reviewer",
 		Findings:     deduped,
 		Duration:     time.Since(start),
+		Metadata:     metadata,
 	}, nil
 }
 
+// passOutcome is what a single review pass produced.
+type passOutcome struct {
+	findings  []interfaces.Finding
+	usage     Usage
+	toolCalls int
+}
+
+// runPasses executes the passes through a worker pool bounded by r.concurrency.
+// The returned slice is indexed like passes so callers can merge deterministically.
+// Returns the context error if ctx is cancelled before all passes complete.
+func (r *Reviewer) runPasses(ctx context.Context, passes []reviewPass) ([]passOutcome, error) {
+	results := make([]passOutcome, len(passes))
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+
+	for i, pass := range passes {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, pass reviewPass) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = r.runPass(ctx, pass)
+		}(i, pass)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+// runPass sends a single review pass to the provider and parses its findings.
+// Failures are logged and yield no findings so one pass cannot sink the review.
+// Tokens are counted even when the response is unusable, since they were spent.
+func (r *Reviewer) runPass(ctx context.Context, pass reviewPass) passOutcome {
+	slog.Info("running AI review pass", "pass", pass.name)
+
+	provider := pass.provider
+	if provider == nil {
+		provider = r.provider
+	}
+
+	var response Completion
+	var err error
+	toolCalls := 0
+	agentic := r.agentic(pass.passName)
+	if agentic {
+		response, toolCalls, err = r.runAgentPass(ctx, pass, provider)
+		if errors.Is(err, ErrToolsUnsupported) {
+			r.noToolsOnce.Do(func() {
+				slog.Warn("AI review: provider cannot call tools, running passes without them")
+			})
+			agentic = false
+		}
+	}
+	if !agentic {
+		response, err = provider.Complete(ctx, pass.userPrompt, CompletionOpts{
+			MaxTokens:    pass.maxTokens,
+			Temperature:  pass.temperature,
+			SystemPrompt: pass.systemPrompt,
+		})
+	}
+	if err != nil {
+		slog.Warn("AI review pass failed", "pass", pass.name, "error", err)
+		return passOutcome{usage: response.Usage, toolCalls: toolCalls}
+	}
+
+	findings, confidence := parseFindings(response.Text, pass.category)
+	if confidence < 0.3 {
+		slog.Warn("AI response poorly structured, skipping findings", "pass", pass.name, "confidence", confidence)
+		return passOutcome{usage: response.Usage, toolCalls: toolCalls}
+	}
+
+	for i := range findings {
+		if findings[i].Metadata == nil {
+			findings[i].Metadata = make(map[string]any)
+		}
+		findings[i].Metadata["pass"] = pass.passName
+		if pass.model != "" {
+			findings[i].Metadata["model"] = pass.model
+		}
+	}
+
+	slog.Info("AI review pass complete", "pass", pass.name, "findings", len(findings), "confidence", confidence, "tool_calls", toolCalls)
+	return passOutcome{findings: findings, usage: response.Usage, toolCalls: toolCalls}
+}
+
 // Available returns true if the LLM provider is configured and reachable.
+// In consensus mode enough models must be reachable to reach agreement.
 func (r *Reviewer) Available(ctx context.Context) bool {
+	if len(r.consensus) > 0 {
+		return r.consensusAvailable(ctx)
+	}
 	return r.provider.Available(ctx)
 }
 
@@ -178,7 +519,7 @@ func parseFindings(response string, category interfaces.Category) ([]interfaces.
 			Title:       lf.Title,
 			Description: lf.Description,
 			Suggestion:  lf.Suggestion,
-			Source:       "ai-reviewer",
+			Source:      "ai-reviewer",
 			Confidence:  0.7, // AI findings get moderate confidence by default.
 		})
 		validCount++
@@ -3,8 +3,12 @@ package ai
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/toyinlola/shipsafe/pkg/interfaces"
 )
@@ -17,16 +21,16 @@ type mockProvider struct {
 	err       error
 }
 
-func (m *mockProvider) Complete(_ context.Context, _ string, _ CompletionOpts) (string, error) {
+func (m *mockProvider) Complete(_ context.Context, _ string, _ CompletionOpts) (Completion, error) {
 	if m.err != nil {
-		return "", m.err
+		return Completion{}, m.err
 	}
 	if m.callIndex >= len(m.responses) {
-		return `{"findings": []}`, nil
+		return Completion{Text: `{"findings": []}`}, nil
 	}
 	resp := m.responses[m.callIndex]
 	m.callIndex++
-	return resp, nil
+	return Completion{Text: resp}, nil
 }
 
 func (m *mockProvider) Available(_ context.Context) bool {
@@ -61,6 +65,7 @@ func TestReviewer_Review_WellFormattedResponse(t *testing.T) {
 						Content:  "+func HandleLogout(w http.ResponseWriter, r *http.Request) {\n",
 						AddedLines: []interfaces.Line{
 							{Number: 42, Content: "func HandleLogout(w http.ResponseWriter, r *http.Request) {"},
+							{Number: 55, Content: "\tname := user.Name"},
 						},
 					},
 				},
@@ -142,9 +147,7 @@ func TestReviewer_Review_MarkdownCodeFences(t *testing.T) {
 
 	reviewer := NewReviewer(provider)
 	diff := &interfaces.Diff{
-		Files: []interfaces.FileDiff{
-			{Path: "main.go", Status: interfaces.FileModified, Language: "go"},
-		},
+		Files: []interfaces.FileDiff{addedFile("main.go", 10)},
 	}
 
 	result, err := reviewer.Review(context.Background(), diff, nil)
@@ -245,9 +248,7 @@ func TestReviewer_Review_PartiallyValidFindings(t *testing.T) {
 
 	reviewer := NewReviewer(provider)
 	diff := &interfaces.Diff{
-		Files: []interfaces.FileDiff{
-			{Path: "main.go", Status: interfaces.FileModified, Language: "go"},
-		},
+		Files: []interfaces.FileDiff{addedFile("main.go", 1, 2)},
 	}
 
 	result, err := reviewer.Review(context.Background(), diff, nil)
@@ -279,7 +280,7 @@ func TestReviewer_Review_ContextCancellation(t *testing.T) {
 	}
 }
 
-func TestBuildContext_BasicDiff(t *testing.T) {
+func TestBuildContextChunks_BasicDiff(t *testing.T) {
 	diff := &interfaces.Diff{
 		PRTitle: "Add authentication",
 		PRBody:  "This PR adds JWT-based auth.",
@@ -303,7 +304,11 @@ func TestBuildContext_BasicDiff(t *testing.T) {
 		},
 	}
 
-	ctx := BuildContext(diff, DefaultMaxTokenBudget)
+	plan := BuildContextChunks(diff, nil, DefaultMaxTokenBudget, DefaultMaxChunks)
+	if len(plan.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(plan.Chunks))
+	}
+	ctx := plan.Chunks[0].Text
 
 	if !strings.Contains(ctx, "Add authentication") {
 		t.Error("expected context to contain PR title")
@@ -319,7 +324,7 @@ func TestBuildContext_BasicDiff(t *testing.T) {
 	}
 }
 
-func TestBuildContext_TruncationOnLargeDiff(t *testing.T) {
+func TestBuildContextChunks_LargeDiffStaysWithinBudget(t *testing.T) {
 	// Create a diff that exceeds the token budget.
 	var files []interfaces.FileDiff
 	for i := 0; i < 50; i++ {
@@ -336,15 +341,24 @@ func TestBuildContext_TruncationOnLargeDiff(t *testing.T) {
 
 	diff := &interfaces.Diff{Files: files}
 	budget := 500 // Very small budget.
-	ctx := BuildContext(diff, budget)
+	plan := BuildContextChunks(diff, nil, budget, DefaultMaxChunks)
 
+	if len(plan.Chunks) != DefaultMaxChunks {
+		t.Fatalf("expected %d chunks, got %d", DefaultMaxChunks, len(plan.Chunks))
+	}
 	maxChars := budget * charsPerToken
-	if len(ctx) > maxChars+200 { // Allow some slack for the truncation message.
-		t.Errorf("context length %d exceeds budget of ~%d chars", len(ctx), maxChars)
+	for i, c := range plan.Chunks {
+		if len(c.Text) > maxChars {
+			t.Errorf("chunk %d has %d chars, exceeding budget of %d", i, len(c.Text), maxChars)
+		}
+	}
+	if len(plan.ReviewedFiles())+len(plan.Skipped) != len(files) {
+		t.Errorf("expected every file to be either reviewed or skipped, got %d reviewed and %d skipped",
+			len(plan.ReviewedFiles()), len(plan.Skipped))
 	}
 }
 
-func TestBuildContext_SecurityFilePriority(t *testing.T) {
+func TestBuildContextChunks_SecurityFilePriority(t *testing.T) {
 	diff := &interfaces.Diff{
 		Files: []interfaces.FileDiff{
 			{
@@ -366,7 +380,11 @@ func TestBuildContext_SecurityFilePriority(t *testing.T) {
 		},
 	}
 
-	ctx := BuildContext(diff, DefaultMaxTokenBudget)
+	plan := BuildContextChunks(diff, nil, DefaultMaxTokenBudget, DefaultMaxChunks)
+	if len(plan.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(plan.Chunks))
+	}
+	ctx := plan.Chunks[0].Text
 
 	// Auth file should appear before utils file.
 	authIdx := strings.Index(ctx, "pkg/auth/login.go")
@@ -381,7 +399,7 @@ func TestBuildContext_SecurityFilePriority(t *testing.T) {
 	}
 }
 
-func TestBuildContext_BinaryFilesSkipped(t *testing.T) {
+func TestBuildContextChunks_BinaryFilesSkipped(t *testing.T) {
 	diff := &interfaces.Diff{
 		Files: []interfaces.FileDiff{
 			{Path: "image.png", Status: interfaces.FileAdded, IsBinary: true},
@@ -389,11 +407,18 @@ func TestBuildContext_BinaryFilesSkipped(t *testing.T) {
 		},
 	}
 
-	ctx := BuildContext(diff, DefaultMaxTokenBudget)
+	plan := BuildContextChunks(diff, nil, DefaultMaxTokenBudget, DefaultMaxChunks)
+	if len(plan.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(plan.Chunks))
+	}
+	ctx := plan.Chunks[0].Text
 
 	if strings.Contains(ctx, "image.png") {
 		t.Error("expected binary files to be skipped in context")
 	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0] != "image.png" {
+		t.Errorf("expected the binary file reported as skipped, got %v", plan.Skipped)
+	}
 	if !strings.Contains(ctx, "main.go") {
 		t.Error("expected non-binary file in context")
 	}
@@ -785,7 +810,7 @@ func TestReviewer_Review_DeduplicatesAcrossPasses(t *testing.T) {
 	reviewer := NewReviewer(provider)
 	diff := &interfaces.Diff{
 		Files: []interfaces.FileDiff{
-			{Path: "pkg/auth/handler.go", Status: interfaces.FileModified, Language: "go"},
+			addedFile("pkg/auth/handler.go", 42, 43, 55),
 		},
 	}
 
@@ -805,3 +830,421 @@ func TestReviewer_Review_DeduplicatesAcrossPasses(t *testing.T) {
 		}
 	}
 }
+
+// passProvider answers each pass based on its system prompt and records the
+// peak number of concurrent calls. It is safe for concurrent use.
+type passProvider struct {
+	mu        sync.Mutex
+	calls     int
+	inFlight  int
+	peak      int
+	delay     time.Duration
+	responses map[string]string // system prompt substring -> response
+}
+
+func (p *passProvider) Complete(ctx context.Context, _ string, opts CompletionOpts) (Completion, error) {
+	p.mu.Lock()
+	p.calls++
+	p.inFlight++
+	if p.inFlight > p.peak {
+		p.peak = p.inFlight
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return Completion{}, ctx.Err()
+	}
+
+	for key, resp := range p.responses {
+		if strings.Contains(opts.SystemPrompt, key) {
+			return Completion{Text: resp}, nil
+		}
+	}
+	return Completion{Text: `{"findings": []}`}, nil
+}
+
+func (p *passProvider) Available(_ context.Context) bool { return true }
+
+func TestReviewer_Review_ConcurrentPassesDeterministicOrder(t *testing.T) {
+	provider := &passProvider{
+		delay: 20 * time.Millisecond,
+		responses: map[string]string{
+			"semantic analysis": `{"findings": [{"file": "a.go", "line": 1, "severity": "low", "title": "Semantic", "description": "intent mismatch in handler naming"}]}`,
+			"logic error":       `{"findings": [{"file": "b.go", "line": 2, "severity": "high", "title": "Logic", "description": "unchecked error from database close"}]}`,
+			"convention":        `{"findings": [{"file": "c.go", "line": 3, "severity": "low", "title": "Convention", "description": "snake case identifier breaks camel case style"}]}`,
+		},
+	}
+
+	reviewer := NewReviewer(provider, WithConcurrency(3))
+	diff := &interfaces.Diff{
+		Files: []interfaces.FileDiff{addedFile("a.go", 1), addedFile("b.go", 2), addedFile("c.go", 3)},
+	}
+
+	result, err := reviewer.Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if provider.peak < 2 {
+		t.Errorf("expected passes to overlap with concurrency 3, peak in-flight was %d", provider.peak)
+	}
+
+	want := []string{"Semantic", "Logic", "Convention"}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %d", len(want), len(result.Findings))
+	}
+	for i, title := range want {
+		if result.Findings[i].Title != title {
+			t.Errorf("finding %d: expected %q, got %q", i, title, result.Findings[i].Title)
+		}
+	}
+}
+
+func TestReviewer_Review_DefaultIsSequential(t *testing.T) {
+	provider := &passProvider{delay: 5 * time.Millisecond}
+	reviewer := NewReviewer(provider)
+
+	_, err := reviewer.Review(context.Background(), &interfaces.Diff{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.peak != 1 {
+		t.Errorf("expected sequential passes by default, peak in-flight was %d", provider.peak)
+	}
+}
+
+func TestReviewer_Review_ConcurrentCancellation(t *testing.T) {
+	provider := &passProvider{delay: time.Second}
+	reviewer := NewReviewer(provider, WithConcurrency(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := reviewer.Review(ctx, &interfaces.Diff{}, nil)
+	if err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected Review to return promptly after cancellation, took %v", elapsed)
+	}
+}
+
+// largeDiff builds a diff of n files, each roughly size bytes of hunk content.
+func largeDiff(n, size int) *interfaces.Diff {
+	var files []interfaces.FileDiff
+	for i := 0; i < n; i++ {
+		line := fmt.Sprintf("+line of file %d\n", i)
+		files = append(files, interfaces.FileDiff{
+			Path:     fmt.Sprintf("pkg/module%d/file.go", i),
+			Status:   interfaces.FileModified,
+			Language: "go",
+			Hunks: []interfaces.Hunk{
+				{NewStart: 1, NewLines: 10, Content: strings.Repeat(line, size/len(line))},
+			},
+		})
+	}
+	return &interfaces.Diff{PRTitle: "Big change", Files: files}
+}
+
+func TestBuildContextChunks_CoversAllFilesWithinBudget(t *testing.T) {
+	diff := largeDiff(10, 1500)
+	budget := 1000 // ~4000 chars, so two files per chunk.
+
+	plan := BuildContextChunks(diff, nil, budget, 20)
+
+	if len(plan.Chunks) < 4 {
+		t.Fatalf("expected the diff to be split into several chunks, got %d", len(plan.Chunks))
+	}
+	if len(plan.Skipped) != 0 {
+		t.Errorf("expected no skipped files, got %v", plan.Skipped)
+	}
+	if got := len(plan.ReviewedFiles()); got != 10 {
+		t.Errorf("expected all 10 files to be reviewed, got %d", got)
+	}
+	for i, c := range plan.Chunks {
+		if len(c.Text) > budget*charsPerToken {
+			t.Errorf("chunk %d has %d chars, exceeding budget of %d", i, len(c.Text), budget*charsPerToken)
+		}
+		if !strings.Contains(c.Text, "PR: Big change") {
+			t.Errorf("chunk %d is missing the PR header", i)
+		}
+	}
+}
+
+func TestBuildContextChunks_SkipsFilesBeyondChunkLimit(t *testing.T) {
+	diff := largeDiff(10, 1500)
+	diff.Files = append(diff.Files, interfaces.FileDiff{Path: "logo.png", IsBinary: true})
+
+	plan := BuildContextChunks(diff, nil, 1000, 2)
+
+	if len(plan.Chunks) != 2 {
+		t.Fatalf("expected exactly 2 chunks, got %d", len(plan.Chunks))
+	}
+	if len(plan.ReviewedFiles())+len(plan.Skipped) != 11 {
+		t.Errorf("expected every file to be either reviewed or skipped, got %d reviewed and %d skipped",
+			len(plan.ReviewedFiles()), len(plan.Skipped))
+	}
+	found := false
+	for _, p := range plan.Skipped {
+		if p == "logo.png" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected binary file to be reported as skipped")
+	}
+}
+
+func TestBuildContextChunks_OversizedFileTruncated(t *testing.T) {
+	diff := largeDiff(1, 20000)
+
+	plan := BuildContextChunks(diff, nil, 500, 4)
+
+	if len(plan.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(plan.Chunks))
+	}
+	if !strings.Contains(plan.Chunks[0].Text, "(file truncated)") {
+		t.Error("expected oversized file to be truncated")
+	}
+	if len(plan.Chunks[0].Text) > 500*charsPerToken {
+		t.Errorf("truncated chunk exceeds budget: %d chars", len(plan.Chunks[0].Text))
+	}
+}
+
+func TestReviewer_Review_RunsEveryPassOverEveryChunk(t *testing.T) {
+	provider := &passProvider{}
+	reviewer := NewReviewer(provider, WithMaxTokenBudget(1000))
+
+	result, err := reviewer.Review(context.Background(), largeDiff(6, 1500), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chunks, _ := result.Metadata["chunks"].(int)
+	if chunks < 2 {
+		t.Fatalf("expected multiple chunks, got %d", chunks)
+	}
+	if provider.calls != 3*chunks {
+		t.Errorf("expected %d LLM calls (3 passes x %d chunks), got %d", 3*chunks, chunks, provider.calls)
+	}
+	reviewed, _ := result.Metadata["reviewed_files"].([]string)
+	if len(reviewed) != 6 {
+		t.Errorf("expected 6 reviewed files in metadata, got %d", len(reviewed))
+	}
+}
+
+func TestBuildProjectContext_FocusAreasAndFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg", "interfaces"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "ARCHITECTURE.md"), "All modules talk through pkg/interfaces.")
+	writeFile(t, filepath.Join(root, "pkg", "interfaces", "types.go"), "package interfaces\n\ntype Finding struct{}\n")
+	writeFile(t, filepath.Join(root, "pkg", "interfaces", "vcs.go"), "package interfaces\n\ntype VCSProvider interface{}\n")
+
+	text, files := BuildProjectContext(root,
+		[]string{"ARCHITECTURE.md", "pkg/interfaces/*.go", "../outside.md"},
+		[]string{"error wrapping"},
+		nil, DefaultMaxTokenBudget)
+
+	if !strings.Contains(text, "- error wrapping") {
+		t.Error("expected focus areas in project context")
+	}
+	if !strings.Contains(text, "All modules talk through pkg/interfaces.") {
+		t.Error("expected ARCHITECTURE.md contents in project context")
+	}
+	want := []string{"ARCHITECTURE.md", "pkg/interfaces/types.go", "pkg/interfaces/vcs.go"}
+	if len(files) != len(want) {
+		t.Fatalf("expected files %v, got %v", want, files)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("file %d: expected %q, got %q", i, want[i], files[i])
+		}
+	}
+}
+
+func TestBuildProjectContext_RespectsBudget(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "big.md"), strings.Repeat("lorem ipsum ", 2000))
+	writeFile(t, filepath.Join(root, "small.md"), "small")
+
+	text, files := BuildProjectContext(root, []string{"big.md", "small.md"}, nil, nil, 200)
+
+	if len(text) > 200*charsPerToken {
+		t.Errorf("project context %d chars exceeds budget of %d", len(text), 200*charsPerToken)
+	}
+	if len(files) != 1 || files[0] != "big.md" {
+		t.Errorf("expected only the truncated big.md to be included, got %v", files)
+	}
+}
+
+func TestReviewer_Review_IncludesProjectContextInPrompts(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "CONTRACTS.md"), "Handlers must return wrapped errors.")
+
+	var prompts []string
+	var mu sync.Mutex
+	provider := &funcProvider{complete: func(prompt string, _ CompletionOpts) string {
+		mu.Lock()
+		prompts = append(prompts, prompt)
+		mu.Unlock()
+		return `{"findings": []}`
+	}}
+
+	reviewer := NewReviewer(provider, WithRepoRoot(root))
+	result, err := reviewer.Review(context.Background(), &interfaces.Diff{}, &interfaces.AIReviewOptions{
+		ContextFiles: []string{"CONTRACTS.md"},
+		FocusAreas:   []string{"error handling"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(prompts) != 3 {
+		t.Fatalf("expected 3 prompts, got %d", len(prompts))
+	}
+	for _, p := range prompts {
+		if !strings.Contains(p, "Handlers must return wrapped errors.") || !strings.Contains(p, "error handling") {
+			t.Errorf("expected project context in prompt, got:\n%s", p)
+		}
+	}
+	if files, _ := result.Metadata["context_files"].([]string); len(files) != 1 {
+		t.Errorf("expected context_files metadata with 1 entry, got %v", result.Metadata["context_files"])
+	}
+}
+
+// funcProvider delegates Complete to a function. Safe for concurrent use if
+// the function is.
+type funcProvider struct {
+	complete func(prompt string, opts CompletionOpts) string
+}
+
+func (f *funcProvider) Complete(_ context.Context, prompt string, opts CompletionOpts) (Completion, error) {
+	return Completion{Text: f.complete(prompt, opts)}, nil
+}
+
+func (f *funcProvider) Available(_ context.Context) bool { return true }
+
+// addedFile returns a modified Go file whose diff adds the given lines.
+func addedFile(path string, lines ...int) interfaces.FileDiff {
+	var hunks []interfaces.Hunk
+	for _, n := range lines {
+		hunks = append(hunks, interfaces.Hunk{
+			NewStart:   n,
+			NewLines:   1,
+			Content:    fmt.Sprintf("+line %d", n),
+			AddedLines: []interfaces.Line{{Number: n, Content: fmt.Sprintf("line %d", n)}},
+		})
+	}
+	return interfaces.FileDiff{Path: path, Status: interfaces.FileModified, Language: "go", Hunks: hunks}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReviewer_Review_CustomPassesAndDisabledBuiltins(t *testing.T) {
+	var mu sync.Mutex
+	var systemPrompts []string
+	var optsSeen []CompletionOpts
+	provider := &funcProvider{complete: func(_ string, opts CompletionOpts) string {
+		mu.Lock()
+		defer mu.Unlock()
+		systemPrompts = append(systemPrompts, opts.SystemPrompt)
+		optsSeen = append(optsSeen, opts)
+		if strings.Contains(opts.SystemPrompt, "personal data") {
+			return `{"findings": [{"file": "user.go", "line": 3, "severity": "high", "title": "PII logged", "description": "email address written to application logs"}]}`
+		}
+		return `{"findings": []}`
+	}}
+
+	temperature := 0.0 // deterministic, not the default
+	reviewer := NewReviewer(provider,
+		WithDisabledPasses("convention", "semantic"),
+		WithCustomPasses(PassConfig{
+			Name:         "gdpr",
+			Category:     interfaces.CategorySecurity,
+			SystemPrompt: "Flag code that logs or exports personal data.",
+			Temperature:  &temperature,
+			MaxTokens:    512,
+		}),
+	)
+
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("user.go", 3)}}
+	result, err := reviewer.Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(systemPrompts) != 2 {
+		t.Fatalf("expected 2 passes (logic + gdpr), got %d", len(systemPrompts))
+	}
+	if !strings.Contains(systemPrompts[1], `"findings"`) {
+		t.Error("expected custom system prompt to include the JSON response contract")
+	}
+	if optsSeen[1].Temperature == nil || *optsSeen[1].Temperature != 0 || optsSeen[1].MaxTokens != 512 {
+		t.Errorf("expected custom pass options to be used, got %+v", optsSeen[1])
+	}
+	if optsSeen[0].MaxTokens != DefaultPassMaxTokens || optsSeen[0].Temperature == nil || *optsSeen[0].Temperature != DefaultPassTemperature {
+		t.Errorf("expected built-in pass to use default options, got %+v", optsSeen[0])
+	}
+
+	if len(result.Findings) != 1 {
+		t.Fatalf("expected 1 finding from the custom pass, got %d", len(result.Findings))
+	}
+	f := result.Findings[0]
+	if f.Category != interfaces.CategorySecurity {
+		t.Errorf("expected custom pass category 'security', got %q", f.Category)
+	}
+	if f.Metadata["pass"] != "gdpr" {
+		t.Errorf("expected finding to record pass 'gdpr', got %v", f.Metadata["pass"])
+	}
+}
+
+// usageProvider returns no findings and reports a fixed usage per call.
+type usageProvider struct{ usage Usage }
+
+func (u *usageProvider) Complete(_ context.Context, _ string, _ CompletionOpts) (Completion, error) {
+	return Completion{Text: `{"findings": []}`, Usage: u.usage}, nil
+}
+
+func (u *usageProvider) Available(_ context.Context) bool { return true }
+
+func TestReviewer_Review_SumsTokenUsagePerPass(t *testing.T) {
+	provider := &usageProvider{usage: Usage{PromptTokens: 100, CompletionTokens: 10}}
+	reviewer := NewReviewer(provider, WithMaxTokenBudget(1000), WithMaxChunks(10))
+
+	result, err := reviewer.Review(context.Background(), largeDiff(6, 1500), nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	chunks := result.Metadata["chunks"].(int)
+	calls := 3 * chunks
+	if got := result.Metadata["prompt_tokens"]; got != 100*calls {
+		t.Errorf("expected prompt_tokens %d, got %v", 100*calls, got)
+	}
+	if got := result.Metadata["completion_tokens"]; got != 10*calls {
+		t.Errorf("expected completion_tokens %d, got %v", 10*calls, got)
+	}
+
+	perPass := result.Metadata["pass_usage"].(map[string]interfaces.TokenUsage)
+	for _, pass := range []string{"semantic", "logic", "convention"} {
+		if perPass[pass].PromptTokens != 100*chunks {
+			t.Errorf("pass %s: expected %d prompt tokens, got %d", pass, 100*chunks, perPass[pass].PromptTokens)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrEndpointBlocked is returned (wrapped) when an endpoint violates the
+// endpoint policy.
+var ErrEndpointBlocked = errors.New("endpoint blocked by sovereignty policy")
+
+// EndpointPolicy restricts which LLM endpoints review prompts may be sent to.
+// The zero value allows every endpoint.
+type EndpointPolicy struct {
+	// AllowedHosts lists permitted endpoint hosts: exact host names
+	// ("llm.internal"), wildcard subdomains ("*.corp.example") or CIDR ranges
+	// ("10.0.0.0/8"). A host name entry matches without DNS; a CIDR entry
+	// matches when every address the host resolves to is inside it. Empty
+	// means any host.
+	AllowedHosts []string
+	// PrivateOnly refuses endpoints that resolve to any address outside the
+	// private, loopback and unique-local ranges.
+	PrivateOnly bool
+	// LookupIP resolves host names. Defaults to net.DefaultResolver.
+	LookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p EndpointPolicy) Enabled() bool {
+	return len(p.AllowedHosts) > 0 || p.PrivateOnly
+}
+
+// String describes the policy for reports, e.g.
+// "private-only; allowed: llm.internal, 10.0.0.0/8".
+func (p EndpointPolicy) String() string {
+	var parts []string
+	if p.PrivateOnly {
+		parts = append(parts, "private-only")
+	}
+	if len(p.AllowedHosts) > 0 {
+		parts = append(parts, "allowed: "+strings.Join(p.AllowedHosts, ", "))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Check returns an error wrapping ErrEndpointBlocked if endpoint may not be
+// used. Anything that cannot be verified, such as an unparsable URL or a
+// failed DNS lookup, is blocked.
+func (p EndpointPolicy) Check(ctx context.Context, endpoint string) error {
+	if !p.Enabled() {
+		return nil
+	}
+	blocked := func(reason string) error {
+		return fmt.Errorf("ai: %s: %s: %w", endpoint, reason, ErrEndpointBlocked)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return blocked("cannot determine host")
+	}
+	host := strings.ToLower(u.Hostname())
+
+	nameAllowed := p.hostNameAllowed(host)
+	var ips []net.IP
+	if !nameAllowed || p.PrivateOnly {
+		ips, err = p.resolve(ctx, host)
+		if err != nil {
+			return blocked(fmt.Sprintf("resolving host: %v", err))
+		}
+	}
+
+	if len(p.AllowedHosts) > 0 && !nameAllowed && !p.addressesAllowed(ips) {
+		return blocked(fmt.Sprintf("host %s is not in the allowlist", host))
+	}
+	if p.PrivateOnly {
+		for _, ip := range ips {
+			if !isPrivateAddress(ip) {
+				return blocked(fmt.Sprintf("host %s resolves to non-private address %s", host, ip))
+			}
+		}
+	}
+	return nil
+}
+
+// hostNameAllowed reports whether host matches a host name entry.
+func (p EndpointPolicy) hostNameAllowed(host string) bool {
+	for _, entry := range p.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if strings.Contains(entry, "/") || net.ParseIP(entry) != nil {
+			continue // Address entries are matched by addressesAllowed.
+		}
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// addressesAllowed reports whether every address is inside an allowed CIDR,
+// or equal to an allowed IP literal.
+func (p EndpointPolicy) addressesAllowed(ips []net.IP) bool {
+	var nets []*net.IPNet
+	for _, entry := range p.AllowedHosts {
+		entry = strings.TrimSpace(entry)
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, n, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	if len(ips) == 0 || len(nets) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		inside := false
+		for _, n := range nets {
+			if n.Contains(ip) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns the addresses of host, which may be an IP literal.
+func (p EndpointPolicy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	lookup := p.LookupIP
+	if lookup == nil {
+		lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		}
+	}
+	ips, err := lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	return ips, nil
+}
+
+// isPrivateAddress reports whether ip is a loopback or RFC 1918 / RFC 4193
+// private address.
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate()
+}
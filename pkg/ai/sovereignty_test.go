@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// fakeLookup resolves names from a fixed table.
+func fakeLookup(table map[string][]string) func(context.Context, string) ([]net.IP, error) {
+	return func(_ context.Context, host string) ([]net.IP, error) {
+		addrs, ok := table[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		ips := make([]net.IP, len(addrs))
+		for i, a := range addrs {
+			ips[i] = net.ParseIP(a)
+		}
+		return ips, nil
+	}
+}
+
+func TestEndpointPolicy_Check(t *testing.T) {
+	lookup := fakeLookup(map[string][]string{
+		"llm.internal":         {"10.1.2.3"},
+		"ollama.corp.example":  {"192.168.4.20"},
+		"api.openai.com":       {"104.18.6.192"},
+		"split.corp.example":   {"10.0.0.9", "34.1.2.3"},
+		"gpu.lab":              {"172.16.8.1"},
+		"localhost":            {"127.0.0.1", "::1"},
+		"rebinding.example":    {"10.0.0.1"},
+		"public-ip.example.io": {"8.8.8.8"},
+	})
+
+	tests := []struct {
+		name     string
+		policy   EndpointPolicy
+		endpoint string
+		allowed  bool
+	}{
+		{"no policy allows anything", EndpointPolicy{}, "https://api.openai.com/v1", true},
+		{"private only allows private host", EndpointPolicy{PrivateOnly: true}, "http://llm.internal:11434/v1", true},
+		{"private only allows loopback", EndpointPolicy{PrivateOnly: true}, "http://localhost:11434/v1", true},
+		{"private only blocks public host", EndpointPolicy{PrivateOnly: true}, "https://api.openai.com/v1", false},
+		{"private only blocks mixed resolution", EndpointPolicy{PrivateOnly: true}, "http://split.corp.example/v1", false},
+		{"private only blocks public IP literal", EndpointPolicy{PrivateOnly: true}, "https://203.0.113.10/v1", false},
+		{"unresolvable host is blocked", EndpointPolicy{PrivateOnly: true}, "http://unknown.internal/v1", false},
+		{"unparsable endpoint is blocked", EndpointPolicy{PrivateOnly: true}, "not a url", false},
+		{"exact host allowed", EndpointPolicy{AllowedHosts: []string{"llm.internal"}}, "http://llm.internal/v1", true},
+		{"exact host is case-insensitive", EndpointPolicy{AllowedHosts: []string{"LLM.internal"}}, "http://llm.INTERNAL/v1", true},
+		{"wildcard subdomain allowed", EndpointPolicy{AllowedHosts: []string{"*.corp.example"}}, "http://ollama.corp.example/v1", true},
+		{"wildcard does not match apex", EndpointPolicy{AllowedHosts: []string{"*.corp.example"}}, "http://corp.example/v1", false},
+		{"host not in allowlist", EndpointPolicy{AllowedHosts: []string{"llm.internal"}}, "https://api.openai.com/v1", false},
+		{"CIDR allows resolved host", EndpointPolicy{AllowedHosts: []string{"172.16.0.0/12"}}, "http://gpu.lab/v1", true},
+		{"CIDR requires every address", EndpointPolicy{AllowedHosts: []string{"10.0.0.0/8"}}, "http://split.corp.example/v1", false},
+		{"IP entry allows literal", EndpointPolicy{AllowedHosts: []string{"203.0.113.10"}}, "https://203.0.113.10/v1", true},
+		{"allowlisted name still checked when private only", EndpointPolicy{AllowedHosts: []string{"public-ip.example.io"}, PrivateOnly: true}, "https://public-ip.example.io/v1", false},
+		{"allowlist and private only both satisfied", EndpointPolicy{AllowedHosts: []string{"10.0.0.0/8"}, PrivateOnly: true}, "http://rebinding.example/v1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.policy.LookupIP = lookup
+			err := tt.policy.Check(context.Background(), tt.endpoint)
+			if tt.allowed && err != nil {
+				t.Errorf("expected %s to be allowed, got %v", tt.endpoint, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrEndpointBlocked) {
+				t.Errorf("expected %s to be blocked, got %v", tt.endpoint, err)
+			}
+		})
+	}
+}
+
+func TestEndpointPolicy_String(t *testing.T) {
+	p := EndpointPolicy{AllowedHosts: []string{"llm.internal", "10.0.0.0/8"}, PrivateOnly: true}
+	if got, want := p.String(), "private-only; allowed: llm.internal, 10.0.0.0/8"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if (EndpointPolicy{}).Enabled() {
+		t.Error("expected the zero policy to be disabled")
+	}
+}
@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// testSuggestionMaxTokens bounds the completion length of a test suggestion.
+const testSuggestionMaxTokens = 2048
+
+// testExampleFiles is how many test files from the diff are shown to the
+// model as examples of the project's test style.
+const testExampleFiles = 2
+
+// TestSuggestionSummary counts what SuggestTests did to the coverage findings.
+type TestSuggestionSummary struct {
+	Suggested int   // findings a test skeleton was attached to
+	Failed    int   // requests that failed or returned no usable skeleton
+	Usage     Usage // tokens spent, including on failed requests
+}
+
+// SuggestTests asks the model for test function skeletons for every file with
+// a coverage finding in results, sending the file's hunks together with test
+// files from the same diff so the skeleton follows the project's language and
+// test framework. The skeleton, the test file it belongs in, its language and
+// the framework are stored in the finding's Metadata ("suggested_tests",
+// "suggested_test_file", "test_language", "test_framework") and mentioned in
+// its Suggestion. Findings are updated in place.
+func (r *Reviewer) SuggestTests(ctx context.Context, diff *interfaces.Diff, results []*interfaces.AnalysisResult) TestSuggestionSummary {
+	files := make(map[string]interfaces.FileDiff, len(diff.Files))
+	for _, f := range diff.Files {
+		files[f.Path] = f
+	}
+
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var summary TestSuggestionSummary
+
+	for _, result := range results {
+		if result == nil || result.Error != nil || result.AnalyzerName == "ai-reviewer" {
+			continue
+		}
+		for i := range result.Findings {
+			f := &result.Findings[i]
+			if f.Category != interfaces.CategoryCoverage {
+				continue
+			}
+			file, ok := files[f.File]
+			if !ok || len(file.Hunks) == 0 {
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(f *interfaces.Finding) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				s, u, err := r.requestTests(ctx, file, testExamples(diff, file), *f)
+				mu.Lock()
+				defer mu.Unlock()
+				summary.Usage = summary.Usage.Add(u)
+				if err != nil {
+					slog.Debug("ai: no usable test suggestion", "file", f.File, "error", err)
+					summary.Failed++
+					return
+				}
+				applyTestSuggestion(f, s, file.Language)
+				summary.Suggested++
+			}(f)
+		}
+	}
+	wg.Wait()
+
+	return summary
+}
+
+// testSuggestion is the expected JSON structure of a test suggestion response.
+type testSuggestion struct {
+	TestFile  string `json:"test_file"`
+	Framework string `json:"framework"`
+	Code      string `json:"code"`
+}
+
+// requestTests asks the provider for test skeletons for file. The file's
+// changes may use half the token budget and the examples a quarter.
+func (r *Reviewer) requestTests(ctx context.Context, file interfaces.FileDiff, examples []interfaces.FileDiff, f interfaces.Finding) (testSuggestion, Usage, error) {
+	changes := truncateToTokens(r.counter, buildFileContext(file), r.maxTokenBudget/2)
+
+	var b strings.Builder
+	remaining := r.maxTokenBudget / 4
+	for _, ex := range examples {
+		text := truncateToTokens(r.counter, buildFileContext(ex), remaining)
+		if text == "" {
+			break
+		}
+		b.WriteString(text)
+		remaining -= r.counter.CountTokens(text)
+	}
+	var exampleText string
+	if b.Len() > 0 {
+		exampleText = prompts.FenceUntrusted(b.String())
+	}
+
+	response, err := r.provider.Complete(ctx,
+		prompts.TestSuggestionPrompt(file.Path, file.Language, f.Description, prompts.FenceUntrusted(changes), exampleText),
+		CompletionOpts{
+			MaxTokens:    testSuggestionMaxTokens,
+			SystemPrompt: prompts.HardenSystemPrompt(prompts.TestSuggestionSystemPrompt()),
+		})
+	if err != nil {
+		return testSuggestion{}, Usage{}, err
+	}
+	s, err := parseTestSuggestion(response.Text)
+	return s, response.Usage, err
+}
+
+// testExamples returns up to testExampleFiles test files from diff to show as
+// examples for file: those in the same language first, then those closest to
+// it in the tree.
+func testExamples(diff *interfaces.Diff, file interfaces.FileDiff) []interfaces.FileDiff {
+	var tests []interfaces.FileDiff
+	for _, f := range diff.Files {
+		if f.IsBinary || f.Status == interfaces.FileDeleted || len(f.Hunks) == 0 || !isTestPath(f.Path) {
+			continue
+		}
+		tests = append(tests, f)
+	}
+	dir := path.Dir(file.Path)
+	rank := func(f interfaces.FileDiff) int {
+		r := 0
+		if f.Language != file.Language {
+			r += 2
+		}
+		if path.Dir(f.Path) != dir {
+			r++
+		}
+		return r
+	}
+	sort.SliceStable(tests, func(a, b int) bool { return rank(tests[a]) < rank(tests[b]) })
+	if len(tests) > testExampleFiles {
+		tests = tests[:testExampleFiles]
+	}
+	return tests
+}
+
+// parseTestSuggestion decodes a test suggestion response.
+func parseTestSuggestion(response string) (testSuggestion, error) {
+	var s testSuggestion
+	if err := json.Unmarshal([]byte(stripCodeFences(response)), &s); err != nil {
+		return testSuggestion{}, fmt.Errorf("decoding test suggestion: %w", err)
+	}
+	s.TestFile = strings.TrimSpace(s.TestFile)
+	s.Framework = strings.TrimSpace(s.Framework)
+	s.Code = strings.Trim(s.Code, "\n")
+	if strings.TrimSpace(s.Code) == "" {
+		return testSuggestion{}, fmt.Errorf("test suggestion has no code")
+	}
+	if s.TestFile == "" {
+		return testSuggestion{}, fmt.Errorf("test suggestion names no test file")
+	}
+	return s, nil
+}
+
+// applyTestSuggestion attaches s, written in language, to the coverage
+// finding f.
+func applyTestSuggestion(f *interfaces.Finding, s testSuggestion, language string) {
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]any)
+	}
+	f.Metadata["suggested_tests"] = s.Code
+	f.Metadata["suggested_test_file"] = s.TestFile
+	if language != "" {
+		f.Metadata["test_language"] = language
+	}
+	if s.Framework != "" {
+		f.Metadata["test_framework"] = s.Framework
+	}
+	f.Suggestion = strings.TrimSpace(fmt.Sprintf("%s Suggested tests for %s are attached.", f.Suggestion, s.TestFile))
+}
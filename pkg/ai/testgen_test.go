@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func TestReviewer_SuggestTests(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{
+		addedFile("pkg/store/ledger.go", 4),
+		addedFile("pkg/store/store_test.go", 12),
+		addedFile("web/app.test.js", 3),
+		addedFile("cmd/main.go", 7),
+	}}
+	diff.Files[2].Language = "javascript"
+	static := []*interfaces.AnalysisResult{
+		{AnalyzerName: "coverage", Findings: []interfaces.Finding{
+			{ID: "COV-MODIFIED-ledger", Category: interfaces.CategoryCoverage, File: "pkg/store/ledger.go",
+				Description: "Expected one of: pkg/store/ledger_test.go", Suggestion: "Add tests for the new or modified code to maintain test coverage."},
+			{ID: "COV-MODIFIED-main", Category: interfaces.CategoryCoverage, File: "cmd/main.go"},
+			{ID: "COV-ADDED-gone", Category: interfaces.CategoryCoverage, File: "gone.go"},
+		}},
+		{AnalyzerName: "patterns", Findings: []interfaces.Finding{
+			{ID: "PAT-1", Category: interfaces.CategorySecurity, File: "pkg/store/ledger.go", StartLine: 4},
+		}},
+	}
+
+	var mu sync.Mutex
+	var sent []string
+	provider := &funcProvider{complete: func(prompt string, opts CompletionOpts) string {
+		mu.Lock()
+		sent = append(sent, prompt)
+		mu.Unlock()
+		if !strings.HasPrefix(opts.SystemPrompt, prompts.TestSuggestionSystemPrompt()) {
+			t.Errorf("expected the test suggestion system prompt, got %q", opts.SystemPrompt)
+		}
+		if !strings.Contains(prompt, "no test changes:\nBEGIN UNTRUSTED ") {
+			t.Errorf("expected the file and finding fenced, got %q", prompt)
+		}
+		if strings.Contains(prompt, "cmd/main.go") {
+			return `{"test_file": "cmd/main_test.go", "code": ""}`
+		}
+		return "```json\n" + `{"test_file": "pkg/store/ledger_test.go", "framework": "testing", "code": "func TestOpenLedger(t *testing.T) {\n\t// TODO\n}\n"}` + "\n```"
+	}}
+
+	summary := NewReviewer(provider, WithConcurrency(2)).SuggestTests(context.Background(), diff, static)
+
+	if len(sent) != 2 {
+		t.Fatalf("expected one request per coverage finding in the diff, got %d", len(sent))
+	}
+	if summary.Suggested != 1 || summary.Failed != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	for _, prompt := range sent {
+		if strings.Contains(prompt, "ledger.go") && (!strings.Contains(prompt, "pkg/store/store_test.go") || !strings.Contains(prompt, "Expected one of: pkg/store/ledger_test.go")) {
+			t.Errorf("expected the test files in the diff and the finding in the prompt, got %q", prompt)
+		}
+	}
+
+	ledger := static[0].Findings[0]
+	if ledger.Metadata["suggested_tests"] != "func TestOpenLedger(t *testing.T) {\n\t// TODO\n}" ||
+		ledger.Metadata["suggested_test_file"] != "pkg/store/ledger_test.go" ||
+		ledger.Metadata["test_framework"] != "testing" ||
+		ledger.Metadata["test_language"] != "go" {
+		t.Errorf("unexpected metadata: %v", ledger.Metadata)
+	}
+	if !strings.HasSuffix(ledger.Suggestion, "Suggested tests for pkg/store/ledger_test.go are attached.") {
+		t.Errorf("expected the suggestion to mention the tests, got %q", ledger.Suggestion)
+	}
+	for _, f := range []interfaces.Finding{static[0].Findings[1], static[0].Findings[2], static[1].Findings[0]} {
+		if _, ok := f.Metadata["suggested_tests"]; ok {
+			t.Errorf("%s should have no suggested tests", f.ID)
+		}
+	}
+}
+
+func TestTestExamples_PrefersSameLanguageAndDirectory(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{
+		addedFile("web/app.test.js", 1),
+		addedFile("cmd/root_test.go", 1),
+		addedFile("pkg/store/store_test.go", 1),
+		addedFile("pkg/store/store.go", 1),
+	}}
+	diff.Files[0].Language = "javascript"
+
+	var got []string
+	for _, f := range testExamples(diff, diff.Files[3]) {
+		got = append(got, f.Path)
+	}
+	if strings.Join(got, ",") != "pkg/store/store_test.go,cmd/root_test.go" {
+		t.Errorf("unexpected examples %v", got)
+	}
+}
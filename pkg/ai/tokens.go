@@ -0,0 +1,271 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenCounter counts how many model tokens a piece of text occupies.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// HeuristicCounter estimates tokens from the byte length of the text.
+// It is the fallback when no tokenizer vocabulary is available.
+type HeuristicCounter struct {
+	CharsPerToken int // 0 = 4
+}
+
+// CountTokens returns ceil(len(text) / CharsPerToken).
+func (h HeuristicCounter) CountTokens(text string) int {
+	cpt := h.CharsPerToken
+	if cpt <= 0 {
+		cpt = charsPerToken
+	}
+	return (len(text) + cpt - 1) / cpt
+}
+
+// BPECounter counts tokens with byte-level byte pair encoding over a
+// tiktoken-style rank table (cl100k_base, o200k_base, ...). Text is first split
+// with the cl100k pre-tokenization rules; o200k's splitter differs only in how
+// it groups mixed-case words, so counts for o200k vocabularies are close but
+// not exact.
+type BPECounter struct {
+	ranks map[string]int
+}
+
+// NewBPECounter creates a counter from a merge-rank table mapping byte
+// sequences to ranks (lower ranks merge first).
+func NewBPECounter(ranks map[string]int) *BPECounter {
+	return &BPECounter{ranks: ranks}
+}
+
+// LoadBPECounter reads a tiktoken vocabulary file (one "<base64 token> <rank>"
+// pair per line), as published for cl100k_base and o200k_base.
+func LoadBPECounter(path string) (*BPECounter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ai: opening tokenizer vocabulary: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	ranks, err := parseBPERanks(f)
+	if err != nil {
+		return nil, fmt.Errorf("ai: reading tokenizer vocabulary %s: %w", path, err)
+	}
+	return NewBPECounter(ranks), nil
+}
+
+// parseBPERanks parses a tiktoken rank table.
+func parseBPERanks(r io.Reader) (map[string]int, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<token> <rank>\"", lineNo)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: decoding token: %w", lineNo, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: parsing rank: %w", lineNo, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("vocabulary is empty")
+	}
+	return ranks, nil
+}
+
+// CountTokens returns the number of BPE tokens in text.
+func (c *BPECounter) CountTokens(text string) int {
+	total := 0
+	for _, piece := range pretokenize(text) {
+		total += c.countPiece(piece)
+	}
+	return total
+}
+
+// countPiece applies byte pair merges to a single pre-tokenized piece.
+func (c *BPECounter) countPiece(piece string) int {
+	if _, ok := c.ranks[piece]; ok {
+		return 1
+	}
+
+	// parts holds the byte offsets where each current token starts.
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+
+	for len(parts) > 2 {
+		bestRank, bestIdx := -1, -1
+		for i := 0; i+2 < len(parts); i++ {
+			rank, ok := c.ranks[piece[parts[i]:parts[i+2]]]
+			if ok && (bestRank == -1 || rank < bestRank) {
+				bestRank, bestIdx = rank, i
+			}
+		}
+		if bestIdx == -1 {
+			break
+		}
+		parts = append(parts[:bestIdx+1], parts[bestIdx+2:]...)
+	}
+	return len(parts) - 1
+}
+
+// pretokenize splits text the way the cl100k_base pattern does:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// It is written by hand because RE2 does not support the lookahead.
+func pretokenize(text string) []string {
+	runes := []rune(text)
+	n := len(runes)
+	var pieces []string
+
+	isNewline := func(r rune) bool { return r == '\r' || r == '\n' }
+	isLetter := unicode.IsLetter
+	isNumber := unicode.IsNumber
+	isSpace := unicode.IsSpace
+	isOther := func(r rune) bool { return !isSpace(r) && !isLetter(r) && !isNumber(r) }
+
+	for i := 0; i < n; {
+		r := runes[i]
+		start := i
+
+		// Contractions.
+		if r == '\'' && i+1 < n {
+			if l := contractionLen(runes[i+1:]); l > 0 {
+				i += 1 + l
+				pieces = append(pieces, string(runes[start:i]))
+				continue
+			}
+		}
+
+		// Optional non-letter/non-digit prefix followed by letters.
+		if isLetter(r) || (!isNewline(r) && !isNumber(r) && i+1 < n && isLetter(runes[i+1])) {
+			i++
+			for i < n && isLetter(runes[i]) {
+				i++
+			}
+			pieces = append(pieces, string(runes[start:i]))
+			continue
+		}
+
+		// Up to three digits.
+		if isNumber(r) {
+			for i < n && i-start < 3 && isNumber(runes[i]) {
+				i++
+			}
+			pieces = append(pieces, string(runes[start:i]))
+			continue
+		}
+
+		// Optional space, punctuation run, trailing newlines.
+		if isOther(r) || (r == ' ' && i+1 < n && isOther(runes[i+1])) {
+			if r == ' ' {
+				i++
+			}
+			for i < n && isOther(runes[i]) {
+				i++
+			}
+			for i < n && isNewline(runes[i]) {
+				i++
+			}
+			pieces = append(pieces, string(runes[start:i]))
+			continue
+		}
+
+		// Whitespace.
+		end := i
+		lastNewline := -1
+		for end < n && isSpace(runes[end]) {
+			if isNewline(runes[end]) {
+				lastNewline = end
+			}
+			end++
+		}
+		switch {
+		case lastNewline >= 0:
+			i = lastNewline + 1
+		case end == n || end-start == 1:
+			i = end
+		default:
+			// Leave the last space to prefix the following token.
+			i = end - 1
+		}
+		pieces = append(pieces, string(runes[start:i]))
+	}
+	return pieces
+}
+
+// contractionLen returns the length of a contraction suffix ('s, 't, 're, 've,
+// 'm, 'll, 'd) at the start of rs, matched case-insensitively, or 0.
+func contractionLen(rs []rune) int {
+	lower := func(i int) rune {
+		if i >= len(rs) {
+			return 0
+		}
+		return unicode.ToLower(rs[i])
+	}
+	switch lower(0) {
+	case 's', 't', 'm', 'd':
+		return 1
+	case 'r', 'v':
+		if lower(1) == 'e' {
+			return 2
+		}
+	case 'l':
+		if lower(1) == 'l' {
+			return 2
+		}
+	}
+	return 0
+}
+
+// truncateToTokens returns the longest prefix of s (on a rune boundary) whose
+// token count does not exceed maxTokens.
+func truncateToTokens(counter TokenCounter, s string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if counter.CountTokens(s) <= maxTokens {
+		return s
+	}
+	// Binary search on byte length, then back off to a rune boundary.
+	lo, hi := 0, len(s)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if counter.CountTokens(s[:mid]) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	for lo > 0 && lo < len(s) && !utf8.RuneStart(s[lo]) {
+		lo--
+	}
+	return s[:lo]
+}
@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+func TestPretokenize(t *testing.T) {
+	got := pretokenize("Hello world's  done\n\n  x123456 (a+b);\n")
+	want := []string{
+		"Hello", " world", "'s", " ", " done", "\n\n", " ", " x", "123", "456",
+		" (", "a", "+b", ");\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("piece %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+	if strings.Join(got, "") != "Hello world's  done\n\n  x123456 (a+b);\n" {
+		t.Error("pieces do not reassemble the input")
+	}
+}
+
+func TestBPECounter_MergesByRank(t *testing.T) {
+	counter := NewBPECounter(map[string]int{
+		"a": 0, "b": 1, "c": 2, "ab": 3, "bc": 4, "abc": 5,
+	})
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"abc", 1},   // whole piece is a token
+		{"abcab", 2}, // ab+c -> abc, then ab
+		{"cba", 3},   // no applicable merges
+		{"abc abc", 3},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := counter.CountTokens(tt.text); got != tt.want {
+			t.Errorf("CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseBPERanks(t *testing.T) {
+	// "YQ==" = "a", "YWI=" = "ab"
+	ranks, err := parseBPERanks(strings.NewReader("YQ== 0\nYWI= 1\n\n"))
+	if err != nil {
+		t.Fatalf("parseBPERanks: %v", err)
+	}
+	if ranks["a"] != 0 || ranks["ab"] != 1 || len(ranks) != 2 {
+		t.Errorf("unexpected ranks: %v", ranks)
+	}
+
+	if _, err := parseBPERanks(strings.NewReader("not-a-rank-line\n")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+	if _, err := parseBPERanks(strings.NewReader("")); err == nil {
+		t.Error("expected error for empty vocabulary")
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	counter := HeuristicCounter{}
+	s := strings.Repeat("héllo ", 50)
+
+	got := truncateToTokens(counter, s, 10)
+	if n := counter.CountTokens(got); n > 10 {
+		t.Errorf("truncated text has %d tokens, want <= 10", n)
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncation split a multi-byte rune")
+	}
+	if !strings.HasPrefix(s, got) {
+		t.Error("expected a prefix of the input")
+	}
+	if truncateToTokens(counter, "short", 10) != "short" {
+		t.Error("text within budget should be returned unchanged")
+	}
+}
+
+// wordCounter counts whitespace-separated words, standing in for a tokenizer
+// whose counts differ from the byte heuristic.
+type wordCounter struct{}
+
+func (wordCounter) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func TestBuildContextChunks_UsesTokenCounter(t *testing.T) {
+	diff := &interfaces.Diff{}
+	for i := 0; i < 4; i++ {
+		diff.Files = append(diff.Files, interfaces.FileDiff{
+			Path:   "pkg/f" + string(rune('a'+i)) + ".go",
+			Status: interfaces.FileModified,
+			Hunks: []interfaces.Hunk{{
+				NewStart: 1, NewLines: 1,
+				Content: "+" + strings.Repeat("xxxxxxxxxxxxxxxx ", 40) + "\n",
+			}},
+		})
+	}
+
+	// Each file is ~45 words but ~700 bytes: the heuristic needs one chunk
+	// per file while the word counter fits everything in one.
+	byWords := BuildContextChunks(diff, wordCounter{}, 300, 10)
+	byBytes := BuildContextChunks(diff, nil, 300, 10)
+
+	if len(byWords.Chunks) != 1 {
+		t.Errorf("expected 1 chunk with the word counter, got %d", len(byWords.Chunks))
+	}
+	if len(byBytes.Chunks) != 4 {
+		t.Errorf("expected 4 chunks with the byte heuristic, got %d", len(byBytes.Chunks))
+	}
+}
@@ -0,0 +1,357 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Limits on what a single repository tool call returns.
+const (
+	toolMaxFileBytes  = 1 << 20 // larger files are not read or searched
+	toolMaxReadLines  = 200
+	toolMaxGrepHits   = 50
+	toolMaxDirEntries = 200
+	toolMaxLineLength = 300
+)
+
+// errOutsideRepo is returned for paths that resolve outside the repository.
+var errOutsideRepo = errors.New("path is outside the repository")
+
+// repoTools are the read-only tools an agentic review pass may call. Every
+// path is resolved inside root, after following symlinks, and the .git
+// directory is hidden. In a git work tree only tracked files are visible, so
+// ignored and untracked files (build output, local .env files) never reach the
+// model; elsewhere dot-files and hidden directories are hidden instead.
+type repoTools struct {
+	root string
+
+	// tracked holds the tracked files and the directories containing them,
+	// as slash-separated paths relative to root. Nil outside a git work tree.
+	tracked map[string]bool
+}
+
+// newRepoTools returns the repository tools for root, listing the files git
+// tracks there.
+func newRepoTools(ctx context.Context, root string) repoTools {
+	files, err := gitTrackedFiles(ctx, root)
+	if err != nil {
+		slog.Debug("ai: not a git work tree, hiding dot-files from repository tools", "root", root, "error", err)
+		return repoTools{root: root}
+	}
+	tracked := map[string]bool{".": true}
+	for _, rel := range files {
+		for p := rel; p != "."; p = path.Dir(p) {
+			tracked[p] = true
+		}
+	}
+	return repoTools{root: root, tracked: tracked}
+}
+
+// visible reports whether the slash-separated path rel, relative to root, may
+// be shown to the model.
+func (t repoTools) visible(rel string) bool {
+	if t.tracked != nil {
+		return t.tracked[rel]
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") && part != "." {
+			return false
+		}
+	}
+	return true
+}
+
+// definitions returns the tool definitions offered to the model.
+func (t repoTools) definitions() []Tool {
+	str := func(desc string) map[string]any { return map[string]any{"type": "string", "description": desc} }
+	num := func(desc string) map[string]any { return map[string]any{"type": "integer", "description": desc} }
+	return []Tool{
+		{
+			Name:        "read_file",
+			Description: fmt.Sprintf("Read lines of a repository file, numbered. At most %d lines per call.", toolMaxReadLines),
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":  str("File path relative to the repository root"),
+					"start": num("First line to read, 1-based (default 1)"),
+					"end":   num("Last line to read, inclusive"),
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "grep",
+			Description: fmt.Sprintf("Search repository files for a regular expression (RE2 syntax). Returns up to %d matches as path:line: text.", toolMaxGrepHits),
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"pattern": str("Regular expression to search for"),
+					"path":    str("Directory or file to search, relative to the repository root (default: whole repository)"),
+				},
+				"required": []string{"pattern"},
+			},
+		},
+		{
+			Name:        "list_dir",
+			Description: "List a repository directory. Subdirectories end with /.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": str("Directory relative to the repository root (default: the root)"),
+				},
+			},
+		},
+	}
+}
+
+// toolArgs are the union of all tool arguments.
+type toolArgs struct {
+	Path    string `json:"path"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Pattern string `json:"pattern"`
+}
+
+// call runs a tool and returns its output. Failures are returned as output
+// too, so the model can correct its request.
+func (t repoTools) call(name, arguments string) string {
+	var args toolArgs
+	if strings.TrimSpace(arguments) != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return fmt.Sprintf("error: invalid arguments: %v", err)
+		}
+	}
+
+	var out string
+	var err error
+	switch name {
+	case "read_file":
+		out, err = t.readFile(args.Path, args.Start, args.End)
+	case "grep":
+		out, err = t.grep(args.Pattern, args.Path)
+	case "list_dir":
+		out, err = t.listDir(args.Path)
+	default:
+		err = fmt.Errorf("unknown tool %q", name)
+	}
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return out
+}
+
+// resolve maps a repository-relative path to a file system path inside the
+// repository, refusing paths that are not visible.
+func (t repoTools) resolve(path string) (string, error) {
+	root, err := filepath.Abs(t.root)
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+
+	clean := filepath.Clean(string(filepath.Separator) + filepath.FromSlash(path))
+	if isGitPath(clean) {
+		return "", errOutsideRepo
+	}
+	full, err := filepath.EvalSymlinks(filepath.Join(root, clean))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%s does not exist", path)
+		}
+		return "", err
+	}
+	rel, err := filepath.Rel(root, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || isGitPath(rel) {
+		return "", errOutsideRepo
+	}
+	if !t.visible(filepath.ToSlash(rel)) {
+		return "", fmt.Errorf("%s is not tracked in the repository", path)
+	}
+	return full, nil
+}
+
+// isGitPath reports whether path is inside a .git directory.
+func isGitPath(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".git" {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile returns lines start..end of a file, numbered like fix prompts.
+func (t repoTools) readFile(path string, start, end int) (string, error) {
+	full, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := readTextFile(full)
+	if err != nil {
+		return "", err
+	}
+
+	if start < 1 {
+		start = 1
+	}
+	if end < start || end-start >= toolMaxReadLines {
+		end = start + toolMaxReadLines - 1
+	}
+
+	var b strings.Builder
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for n := start; n <= end && n <= len(lines); n++ {
+		fmt.Fprintf(&b, "%d | %s\n", n, truncateStr(lines[n-1], toolMaxLineLength))
+	}
+	if b.Len() == 0 {
+		return fmt.Sprintf("%s has %d lines", path, len(lines)), nil
+	}
+	if end < len(lines) {
+		fmt.Fprintf(&b, "(%d more lines)\n", len(lines)-end)
+	}
+	return b.String(), nil
+}
+
+// grep searches text files under path for pattern.
+func (t repoTools) grep(pattern, path string) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("pattern is required")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	base, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := t.resolve("")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	hits := 0
+	errLimit := errors.New("limit reached")
+	err = filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped.
+		}
+		rel, _ := filepath.Rel(root, p)
+		if d.IsDir() {
+			if d.Name() == ".git" || !t.visible(filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !t.visible(filepath.ToSlash(rel)) {
+			return nil
+		}
+		data, err := readTextFile(p)
+		if err != nil {
+			return nil
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), toolMaxFileBytes)
+		for n := 1; scanner.Scan(); n++ {
+			if !re.Match(scanner.Bytes()) {
+				continue
+			}
+			if hits == toolMaxGrepHits {
+				return errLimit
+			}
+			hits++
+			fmt.Fprintf(&b, "%s:%d: %s\n", filepath.ToSlash(rel), n, truncateStr(scanner.Text(), toolMaxLineLength))
+		}
+		return nil
+	})
+	if errors.Is(err, errLimit) {
+		fmt.Fprintf(&b, "(stopped after %d matches)\n", toolMaxGrepHits)
+	} else if err != nil {
+		return "", err
+	}
+	if hits == 0 {
+		return "no matches", nil
+	}
+	return b.String(), nil
+}
+
+// listDir lists the entries of a directory.
+func (t repoTools) listDir(path string) (string, error) {
+	full, err := t.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := t.resolve("")
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Rel(root, full)
+	if err != nil {
+		return "", err
+	}
+	all, err := os.ReadDir(full)
+	if err != nil {
+		return "", err
+	}
+	var entries []fs.DirEntry
+	for _, e := range all {
+		if e.Name() != ".git" && t.visible(filepath.ToSlash(filepath.Join(dir, e.Name()))) {
+			entries = append(entries, e)
+		}
+	}
+
+	var b strings.Builder
+	listed := 0
+	for _, e := range entries {
+		if listed == toolMaxDirEntries {
+			fmt.Fprintf(&b, "(%d more entries)\n", len(entries)-listed)
+			break
+		}
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		b.WriteString(name + "\n")
+		listed++
+	}
+	if listed == 0 {
+		return "empty directory", nil
+	}
+	return b.String(), nil
+}
+
+// readTextFile reads a file that is small enough and not binary.
+func readTextFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", filepath.Base(path))
+	}
+	if info.Size() > toolMaxFileBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", toolMaxFileBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, fmt.Errorf("binary file")
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// toolRepo creates a small repository checkout next to a file outside it.
+func toolRepo(t *testing.T) repoTools {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "repo")
+	for _, d := range []string{"pkg/store", ".git"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(root, "pkg/store/store.go"), "package store\n\nfunc (s *Store) Close() error {\n\treturn s.db.Close()\n}\n")
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(root, ".git/config"), "[remote] url = https://token@example.com/repo\n")
+	writeFile(t, filepath.Join(root, ".env"), "API_TOKEN=secret\n")
+	writeFile(t, filepath.Join(dir, "outside.txt"), "secret\n")
+	if err := os.Symlink(filepath.Join(dir, "outside.txt"), filepath.Join(root, "escape.txt")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	return repoTools{root: root}
+}
+
+func TestRepoTools_ReadFile(t *testing.T) {
+	tools := toolRepo(t)
+
+	got := tools.call("read_file", `{"path": "pkg/store/store.go", "start": 3, "end": 4}`)
+	want := "3 | func (s *Store) Close() error {\n4 | \treturn s.db.Close()\n(1 more lines)\n"
+	if got != want {
+		t.Errorf("read_file = %q, want %q", got, want)
+	}
+	if got := tools.call("read_file", `{"path": "/main.go"}`); !strings.HasPrefix(got, "1 | package main") {
+		t.Errorf("expected absolute paths to be taken relative to the root, got %q", got)
+	}
+}
+
+func TestRepoTools_Sandbox(t *testing.T) {
+	tools := toolRepo(t)
+
+	for _, args := range []string{
+		`{"path": "../outside.txt"}`,
+		`{"path": "pkg/../../outside.txt"}`,
+		`{"path": "escape.txt"}`,
+		`{"path": ".git/config"}`,
+		`{"path": ".env"}`,
+	} {
+		got := tools.call("read_file", args)
+		if strings.Contains(got, "secret") || strings.Contains(got, "token") || !strings.HasPrefix(got, "error:") {
+			t.Errorf("read_file(%s) escaped the sandbox: %q", args, got)
+		}
+	}
+	if got := tools.call("list_dir", `{"path": ".."}`); strings.Contains(got, "outside.txt") {
+		t.Errorf("list_dir escaped the sandbox: %q", got)
+	}
+	if got := tools.call("grep", `{"pattern": "secret|token"}`); got != "no matches" {
+		t.Errorf("grep searched outside the sandbox: %q", got)
+	}
+}
+
+func TestRepoTools_GrepAndListDir(t *testing.T) {
+	tools := toolRepo(t)
+
+	if got, want := tools.call("grep", `{"pattern": "Close\\("}`), "pkg/store/store.go:3: func (s *Store) Close() error {\npkg/store/store.go:4: \treturn s.db.Close()\n"; got != want {
+		t.Errorf("grep = %q, want %q", got, want)
+	}
+	if got := tools.call("grep", `{"pattern": "("}`); !strings.HasPrefix(got, "error: invalid pattern") {
+		t.Errorf("expected an invalid pattern error, got %q", got)
+	}
+	if got, want := tools.call("list_dir", `{}`), "escape.txt\nmain.go\npkg/\n"; got != want {
+		t.Errorf("list_dir = %q, want %q", got, want)
+	}
+	if got := tools.call("delete_file", `{}`); !strings.HasPrefix(got, "error: unknown tool") {
+		t.Errorf("expected unknown tools to be refused, got %q", got)
+	}
+}
+
+func TestRepoTools_OnlyTrackedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(root, ".gitignore"), "build/\n")
+	writeFile(t, filepath.Join(root, ".env"), "DB_PASSWORD=hunter2\n")
+	writeFile(t, filepath.Join(root, "build/bundle.js"), "const dbPassword = \"hunter2\";\n")
+	for _, args := range [][]string{{"init", "-q"}, {"add", "main.go", ".gitignore"}} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	tools := newRepoTools(context.Background(), root)
+
+	for _, args := range []string{`{"path": ".env"}`, `{"path": "build/bundle.js"}`} {
+		if got := tools.call("read_file", args); !strings.HasPrefix(got, "error:") || strings.Contains(got, "hunter2") {
+			t.Errorf("read_file(%s) read an untracked file: %q", args, got)
+		}
+	}
+	if got := tools.call("grep", `{"pattern": "hunter2"}`); got != "no matches" {
+		t.Errorf("grep searched untracked files: %q", got)
+	}
+	if got, want := tools.call("list_dir", `{}`), ".gitignore\nmain.go\n"; got != want {
+		t.Errorf("list_dir = %q, want %q", got, want)
+	}
+	if got := tools.call("read_file", `{"path": "main.go"}`); !strings.HasPrefix(got, "1 | package main") {
+		t.Errorf("expected tracked files to be readable, got %q", got)
+	}
+}
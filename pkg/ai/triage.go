@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// triageMaxTokens bounds the completion length of a triage request.
+const triageMaxTokens = 256
+
+// triageMinConfidence is the lowest confidence triage may leave a finding
+// with. The scorer treats zero as "unset", so a disputed finding must keep
+// some weight.
+const triageMinConfidence = 0.1
+
+// TriageSummary counts what triage did to the static findings.
+type TriageSummary struct {
+	Triaged   int   // findings the model returned a verdict for
+	Confirmed int   // judged true positives
+	Disputed  int   // judged false positives
+	Failed    int   // requests that failed or returned no usable verdict
+	Usage     Usage // tokens spent, including on failed requests
+}
+
+// Triage asks the model for a second opinion on every medium-or-higher
+// finding in results that lies inside a diff hunk, sending the finding with
+// its hunk. Findings are never removed: a false positive verdict lowers the
+// finding's Confidence, a true positive raises it, and the verdict, the
+// model's certainty, its reasoning and the original confidence are stored in
+// the finding's Metadata ("triage_verdict", "triage_certainty",
+// "triage_reasoning", "triage_original_confidence"). AI review results are
+// skipped, as are secrets findings: the provider redacts the secret before the
+// model sees it, and a masked value is indistinguishable from a placeholder.
+// Findings are updated in place.
+func (r *Reviewer) Triage(ctx context.Context, diff *interfaces.Diff, results []*interfaces.AnalysisResult) TriageSummary {
+	files := make(map[string]interfaces.FileDiff, len(diff.Files))
+	for _, f := range diff.Files {
+		files[f.Path] = f
+	}
+
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var summary TriageSummary
+
+	for _, result := range results {
+		if result == nil || result.Error != nil || result.AnalyzerName == "ai-reviewer" {
+			continue
+		}
+		for i := range result.Findings {
+			f := &result.Findings[i]
+			if severityRank(f.Severity) < severityRank(interfaces.SeverityMedium) || f.Category == interfaces.CategorySecrets {
+				continue
+			}
+			file, ok := files[f.File]
+			if !ok {
+				continue
+			}
+			hunk, ok := hunkForLine(file, f.StartLine)
+			if !ok {
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(analyzer string, f *interfaces.Finding) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				v, u, err := r.requestVerdict(ctx, analyzer, hunk, *f)
+				mu.Lock()
+				defer mu.Unlock()
+				summary.Usage = summary.Usage.Add(u)
+				if err != nil {
+					slog.Debug("ai: no usable triage verdict", "file", f.File, "line", f.StartLine, "error", err)
+					summary.Failed++
+					return
+				}
+				applyVerdict(f, v)
+				summary.Triaged++
+				switch v.Verdict {
+				case prompts.VerdictTruePositive:
+					summary.Confirmed++
+				case prompts.VerdictFalsePositive:
+					summary.Disputed++
+				}
+			}(result.AnalyzerName, f)
+		}
+	}
+	wg.Wait()
+
+	return summary
+}
+
+// triageVerdict is the expected JSON structure of a triage response.
+type triageVerdict struct {
+	Verdict    string  `json:"verdict"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+}
+
+// requestVerdict asks the provider whether f is a true positive.
+func (r *Reviewer) requestVerdict(ctx context.Context, analyzer string, hunk interfaces.Hunk, f interfaces.Finding) (triageVerdict, Usage, error) {
+	var code strings.Builder
+	for _, l := range newSideLines(hunk) {
+		fmt.Fprintf(&code, "%d | %s\n", l.number, l.content)
+	}
+
+	response, err := r.provider.Complete(ctx,
+		prompts.TriagePrompt(analyzer, f.File, f.StartLine, string(f.Severity), f.Title, f.Description, prompts.FenceUntrusted(code.String())),
+		CompletionOpts{
+			MaxTokens:    triageMaxTokens,
+			SystemPrompt: prompts.HardenSystemPrompt(prompts.TriageSystemPrompt()),
+		})
+	if err != nil {
+		return triageVerdict{}, Usage{}, err
+	}
+	v, err := parseVerdict(response.Text)
+	return v, response.Usage, err
+}
+
+// parseVerdict decodes a triage response. Certainty outside [0, 1] is
+// clamped; a missing certainty counts as 0.5.
+func parseVerdict(response string) (triageVerdict, error) {
+	var v triageVerdict
+	if err := json.Unmarshal([]byte(stripCodeFences(response)), &v); err != nil {
+		return triageVerdict{}, fmt.Errorf("decoding verdict: %w", err)
+	}
+	v.Verdict = strings.ToLower(strings.TrimSpace(v.Verdict))
+	switch v.Verdict {
+	case prompts.VerdictTruePositive, prompts.VerdictFalsePositive, prompts.VerdictUncertain:
+	default:
+		return triageVerdict{}, fmt.Errorf("unknown verdict %q", v.Verdict)
+	}
+	if strings.TrimSpace(v.Reasoning) == "" {
+		return triageVerdict{}, fmt.Errorf("verdict has no reasoning")
+	}
+	switch {
+	case v.Confidence == 0:
+		v.Confidence = 0.5
+	case v.Confidence < 0:
+		v.Confidence = 0
+	case v.Confidence > 1:
+		v.Confidence = 1
+	}
+	return v, nil
+}
+
+// applyVerdict adjusts f's confidence by the verdict and records it. A false
+// positive scales confidence down by the model's certainty, a true positive
+// moves it towards 1 by the same fraction, and an uncertain verdict leaves it
+// unchanged.
+func applyVerdict(f *interfaces.Finding, v triageVerdict) {
+	original := f.Confidence
+	if original <= 0 {
+		original = 1.0 // Unset confidence counts in full, as in the scorer.
+	}
+
+	confidence := original
+	switch v.Verdict {
+	case prompts.VerdictFalsePositive:
+		confidence = max(original*(1-v.Confidence), triageMinConfidence)
+	case prompts.VerdictTruePositive:
+		confidence = original + (1-original)*v.Confidence
+	}
+	f.Confidence = confidence
+
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]any)
+	}
+	f.Metadata["triage_verdict"] = v.Verdict
+	f.Metadata["triage_certainty"] = v.Confidence
+	f.Metadata["triage_reasoning"] = strings.TrimSpace(v.Reasoning)
+	f.Metadata["triage_original_confidence"] = original
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/toyinlola/shipsafe/pkg/ai/prompts"
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+const (
+	// walkthroughMaxTokens bounds the completion length of the walkthrough.
+	walkthroughMaxTokens = 1024
+	// maxWalkthroughRisks caps how many risk areas are kept.
+	maxWalkthroughRisks = 3
+)
+
+// llmWalkthrough is the expected JSON structure of a walkthrough response.
+type llmWalkthrough struct {
+	Summary string `json:"summary"`
+	Files   []struct {
+		File    string `json:"file"`
+		Summary string `json:"summary"`
+	} `json:"files"`
+	Risks []struct {
+		File   string `json:"file"`
+		Reason string `json:"reason"`
+	} `json:"risks"`
+}
+
+// writeWalkthrough asks the provider for an overview of the change. The model
+// sees the full list of changed files and the first context chunk, so the
+// walkthrough costs a single call however large the diff is. File summaries
+// and risks on files outside the diff are dropped.
+func (r *Reviewer) writeWalkthrough(ctx context.Context, diff *interfaces.Diff, chunkText string) (*interfaces.Walkthrough, Usage, error) {
+	temperature := DefaultPassTemperature
+	resp, err := r.provider.Complete(ctx, prompts.WalkthroughPrompt(prompts.FenceUntrusted(changedFileList(diff)), prompts.FenceUntrusted(chunkText)), CompletionOpts{
+		MaxTokens:    walkthroughMaxTokens,
+		Temperature:  &temperature,
+		SystemPrompt: prompts.HardenSystemPrompt(prompts.WalkthroughSystemPrompt()),
+	})
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("ai: walkthrough: %w", err)
+	}
+	w, err := parseWalkthrough(resp.Text, diff)
+	if err != nil {
+		return nil, resp.Usage, err
+	}
+	return w, resp.Usage, nil
+}
+
+// parseWalkthrough decodes a walkthrough response, keeping only entries that
+// refer to files in the diff. A response without a summary is an error.
+func parseWalkthrough(response string, diff *interfaces.Diff) (*interfaces.Walkthrough, error) {
+	var lw llmWalkthrough
+	if err := json.Unmarshal([]byte(stripCodeFences(response)), &lw); err != nil {
+		return nil, fmt.Errorf("ai: parsing walkthrough: %w", err)
+	}
+	if strings.TrimSpace(lw.Summary) == "" {
+		return nil, fmt.Errorf("ai: walkthrough response has no summary")
+	}
+
+	files := make(map[string][]int, len(diff.Files))
+	for _, f := range diff.Files {
+		files[f.Path] = nil
+	}
+
+	w := &interfaces.Walkthrough{Summary: strings.TrimSpace(lw.Summary)}
+	for _, f := range lw.Files {
+		path, ok := matchDiffPath(files, f.File)
+		if !ok || strings.TrimSpace(f.Summary) == "" {
+			continue
+		}
+		w.Files = append(w.Files, interfaces.FileWalkthrough{File: path, Summary: strings.TrimSpace(f.Summary)})
+	}
+	for _, risk := range lw.Risks {
+		if strings.TrimSpace(risk.Reason) == "" || len(w.Risks) == maxWalkthroughRisks {
+			continue
+		}
+		path := ""
+		if risk.File != "" {
+			p, ok := matchDiffPath(files, risk.File)
+			if !ok {
+				continue
+			}
+			path = p
+		}
+		w.Risks = append(w.Risks, interfaces.RiskArea{File: path, Reason: strings.TrimSpace(risk.Reason)})
+	}
+	return w, nil
+}
+
+// changedFileList renders one line per changed file with its status and
+// line counts, e.g. "- pkg/api/handler.go (modified, +12 -3)".
+func changedFileList(diff *interfaces.Diff) string {
+	var b strings.Builder
+	for _, f := range diff.Files {
+		added, removed := 0, 0
+		for _, h := range f.Hunks {
+			added += len(h.AddedLines)
+			removed += len(h.RemovedLines)
+		}
+		fmt.Fprintf(&b, "- %s (%s, +%d -%d)\n", f.Path, f.Status, added, removed)
+	}
+	return b.String()
+}
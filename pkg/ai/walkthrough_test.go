@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
+)
+
+// walkthroughProvider answers walkthrough requests with walkthrough and every
+// review pass with no findings.
+type walkthroughProvider struct {
+	walkthrough string
+	err         error
+	prompt      string // last walkthrough prompt
+}
+
+func (p *walkthroughProvider) Complete(_ context.Context, prompt string, opts CompletionOpts) (Completion, error) {
+	if strings.Contains(opts.SystemPrompt, "walkthrough") {
+		p.prompt = prompt
+		if p.err != nil {
+			return Completion{}, p.err
+		}
+		return Completion{Text: p.walkthrough, Usage: Usage{PromptTokens: 300, CompletionTokens: 80}}, nil
+	}
+	return Completion{Text: `{"findings": []}`}, nil
+}
+
+func (p *walkthroughProvider) Available(_ context.Context) bool { return true }
+
+func TestParseWalkthrough(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{
+		addedFile("pkg/auth/session.go", 10),
+		addedFile("pkg/auth/session_test.go", 5),
+	}}
+	response := "```json\n" + `{
+		"summary": "Adds session expiry to the auth package.",
+		"files": [
+			{"file": "pkg/auth/session.go", "summary": "Expires sessions after the configured TTL."},
+			{"file": "pkg/auth/cookie.go", "summary": "Not part of the change."},
+			{"file": "./pkg/auth/session_test.go", "summary": "Covers expiry."}
+		],
+		"risks": [
+			{"file": "pkg/auth/session.go", "reason": "Clock skew could log users out early."},
+			{"reason": "No migration for existing sessions."},
+			{"file": "pkg/db/schema.go", "reason": "Invented file."},
+			{"reason": "Third."},
+			{"reason": "Fourth is over the limit."}
+		]
+	}` + "\n```"
+
+	w, err := parseWalkthrough(response, diff)
+	if err != nil {
+		t.Fatalf("parseWalkthrough: %v", err)
+	}
+	if w.Summary != "Adds session expiry to the auth package." {
+		t.Errorf("unexpected summary %q", w.Summary)
+	}
+	if len(w.Files) != 2 || w.Files[1].File != "pkg/auth/session_test.go" {
+		t.Errorf("expected 2 file summaries with normalised paths, got %+v", w.Files)
+	}
+	if len(w.Risks) != maxWalkthroughRisks {
+		t.Fatalf("expected %d risks, got %+v", maxWalkthroughRisks, w.Risks)
+	}
+	if w.Risks[0].File != "pkg/auth/session.go" || w.Risks[1].File != "" {
+		t.Errorf("unexpected risk files: %+v", w.Risks)
+	}
+}
+
+func TestParseWalkthrough_RequiresSummary(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 1)}}
+	for _, response := range []string{`not json`, `{"summary": "  ", "files": []}`} {
+		if _, err := parseWalkthrough(response, diff); err == nil {
+			t.Errorf("expected error for %q", response)
+		}
+	}
+}
+
+func TestReviewer_Review_Walkthrough(t *testing.T) {
+	diff := &interfaces.Diff{PRTitle: "Add expiry", Files: []interfaces.FileDiff{addedFile("main.go", 3)}}
+	provider := &walkthroughProvider{walkthrough: `{"summary": "Adds expiry.", "files": [{"file": "main.go", "summary": "New TTL check."}], "risks": []}`}
+
+	result, err := NewReviewer(provider, WithWalkthrough(true)).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	w, ok := result.Metadata["walkthrough"].(*interfaces.Walkthrough)
+	if !ok || w.Summary != "Adds expiry." || len(w.Files) != 1 {
+		t.Fatalf("expected walkthrough in metadata, got %#v", result.Metadata["walkthrough"])
+	}
+	if !strings.Contains(provider.prompt, "Changed files:\nBEGIN UNTRUSTED ") {
+		t.Errorf("expected the changed file list fenced, got %q", provider.prompt)
+	}
+	usage := result.Metadata["pass_usage"].(map[string]interfaces.TokenUsage)
+	if usage["walkthrough"].PromptTokens != 300 {
+		t.Errorf("expected walkthrough usage recorded, got %+v", usage["walkthrough"])
+	}
+}
+
+func TestReviewer_Review_WalkthroughFailureKeepsReview(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 3)}}
+	provider := &walkthroughProvider{err: context.DeadlineExceeded}
+
+	result, err := NewReviewer(provider, WithWalkthrough(true)).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if _, ok := result.Metadata["walkthrough"]; ok {
+		t.Error("expected no walkthrough when the walkthrough call fails")
+	}
+}
+
+func TestReviewer_Review_NoWalkthroughByDefault(t *testing.T) {
+	diff := &interfaces.Diff{Files: []interfaces.FileDiff{addedFile("main.go", 3)}}
+	provider := &walkthroughProvider{walkthrough: `{"summary": "Should not be requested."}`}
+
+	result, err := NewReviewer(provider).Review(context.Background(), diff, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if _, ok := result.Metadata["walkthrough"]; ok {
+		t.Error("expected no walkthrough unless enabled")
+	}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// privateKeyPatternName is the secretPatterns rule matching a PEM private
+	// key header.
+	privateKeyPatternName = "RSA/SSH Private Key"
+	// privateKeyEndMarker closes a PEM private key block.
+	privateKeyEndMarker = "PRIVATE KEY-----"
+)
+
+// RedactSecrets replaces everything the secrets analyzer would flag in text
+// with a placeholder such as "[REDACTED:GITHUB-PERSONAL-ACCESS-TOKEN]", so the
+// text can be shared (for example with a hosted LLM) without leaking
+// credentials. Unlike the analyzer it does not skip lines that look like test
+// or example data: redacting a fake key costs nothing, missing a real one
+// does. The body of a PEM private key block is removed entirely.
+//
+// It returns the redacted text and the distinct values that were removed.
+func RedactSecrets(text string) (string, []string) {
+	s := NewSecretsAnalyzer()
+	lines := strings.Split(text, "\n")
+	seen := make(map[string]bool)
+	var redacted []string
+	record := func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			redacted = append(redacted, v)
+		}
+	}
+
+	inKey := false
+	for i, line := range lines {
+		if inKey {
+			if strings.Contains(line, privateKeyEndMarker) {
+				inKey = false
+				continue
+			}
+			if strings.TrimSpace(line) != "" {
+				record(line)
+				lines[i] = keepDiffPrefix(line) + "[REDACTED:PRIVATE-KEY]"
+			}
+			continue
+		}
+
+		for _, p := range secretPatterns {
+			if p.name == privateKeyPatternName {
+				// Keep the header; the key body on the following lines is removed.
+				inKey = inKey || p.regex.MatchString(line)
+				continue
+			}
+			line = redactPattern(line, p, record)
+		}
+		for _, token := range s.highEntropyTokens("", line) {
+			record(token)
+			line = strings.ReplaceAll(line, token, "[REDACTED:HIGH-ENTROPY]")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n"), redacted
+}
+
+// redactPattern replaces each match of p in line with a placeholder. Only the
+// "secret" group is replaced when the pattern has one, so key names stay
+// visible.
+func redactPattern(line string, p secretPattern, record func(string)) string {
+	group := p.regex.SubexpIndex("secret")
+	placeholder := fmt.Sprintf("[REDACTED:%s]", sanitizeID(p.name))
+
+	var b strings.Builder
+	last := 0
+	for _, m := range p.regex.FindAllStringSubmatchIndex(line, -1) {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		record(line[start:end])
+		b.WriteString(line[last:start])
+		b.WriteString(placeholder)
+		last = end
+	}
+	if last == 0 {
+		return line
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// keepDiffPrefix returns the unified-diff marker ("+", "-" or " ") that
+// starts line, if any, so redacted diff lines stay well-formed.
+func keepDiffPrefix(line string) string {
+	if line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+		return line[:1]
+	}
+	return ""
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/toyinlola/shipsafe/pkg/interfaces"
 )
 
-// secretPattern defines a single regex-based secret detection rule.
+// secretPattern defines a single regex-based secret detection rule. Where
+// the regex also matches surrounding text (a key name, quotes), the secret
+// value itself is captured in a group named "secret".
 type secretPattern struct {
 	name     string
 	regex    *regexp.Regexp
@@ -21,12 +23,12 @@ type secretPattern struct {
 var secretPatterns = []secretPattern{
 	{
 		name:     "AWS Access Key ID",
-		regex:    regexp.MustCompile(`(?:^|[^A-Za-z0-9/+=])AKIA[0-9A-Z]{16}(?:[^A-Za-z0-9/+=]|$)`),
+		regex:    regexp.MustCompile(`(?:^|[^A-Za-z0-9/+=])(?P<secret>AKIA[0-9A-Z]{16})(?:[^A-Za-z0-9/+=]|$)`),
 		severity: interfaces.SeverityHigh,
 	},
 	{
 		name:     "AWS Secret Access Key",
-		regex:    regexp.MustCompile(`(?i)(?:aws_secret_access_key|aws_secret_key|secret_access_key)\s*[:=]\s*[A-Za-z0-9/+=]{40}`),
+		regex:    regexp.MustCompile(`(?i)(?:aws_secret_access_key|aws_secret_key|secret_access_key)\s*[:=]\s*(?P<secret>[A-Za-z0-9/+=]{40})`),
 		severity: interfaces.SeverityCritical,
 	},
 	{
@@ -36,12 +38,12 @@ var secretPatterns = []secretPattern{
 	},
 	{
 		name:     "Generic API Key Assignment",
-		regex:    regexp.MustCompile(`(?i)(?:api_key|apikey|api-key|api_secret|apisecret)\s*[:=]\s*["']?[A-Za-z0-9_\-]{16,}["']?`),
+		regex:    regexp.MustCompile(`(?i)(?:api_key|apikey|api-key|api_secret|apisecret)\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9_\-]{16,})["']?`),
 		severity: interfaces.SeverityHigh,
 	},
 	{
 		name:     "Bearer Token",
-		regex:    regexp.MustCompile(`(?i)(?:bearer\s+)[A-Za-z0-9_\-.]{20,}`),
+		regex:    regexp.MustCompile(`(?i)(?:bearer\s+)(?P<secret>[A-Za-z0-9_\-.]{20,})`),
 		severity: interfaces.SeverityHigh,
 	},
 	{
@@ -51,7 +53,7 @@ var secretPatterns = []secretPattern{
 	},
 	{
 		name:     "Password Assignment",
-		regex:    regexp.MustCompile(`(?i)(?:password|passwd|pwd)\s*[:=]\s*["'][^"']{8,}["']`),
+		regex:    regexp.MustCompile(`(?i)(?:password|passwd|pwd)\s*[:=]\s*["'](?P<secret>[^"']{8,})["']`),
 		severity: interfaces.SeverityHigh,
 	},
 	{
@@ -61,7 +63,7 @@ var secretPatterns = []secretPattern{
 	},
 	{
 		name:     "Generic Secret Assignment",
-		regex:    regexp.MustCompile(`(?i)(?:secret|token|auth)_?(?:key)?\s*[:=]\s*["'][A-Za-z0-9_\-/+=]{20,}["']`),
+		regex:    regexp.MustCompile(`(?i)(?:secret|token|auth)_?(?:key)?\s*[:=]\s*["'](?P<secret>[A-Za-z0-9_\-/+=]{20,})["']`),
 		severity: interfaces.SeverityHigh,
 	},
 }
@@ -305,16 +307,47 @@ func (s *SecretsAnalyzer) scanLine(path string, line interfaces.Line) []interfac
 
 // checkEntropy looks for high-entropy tokens on a line that may be secrets.
 func (s *SecretsAnalyzer) checkEntropy(path string, line interfaces.Line) (interfaces.Finding, bool) {
-	if isChecksumLine(line.Content) {
+	tokens := s.highEntropyTokens(path, line.Content)
+	if len(tokens) == 0 {
 		return interfaces.Finding{}, false
 	}
+	token := tokens[0]
+	entropy := shannonEntropy(token)
+	return interfaces.Finding{
+		ID:        fmt.Sprintf("SEC-ENTROPY-%d", line.Number),
+		Category:  interfaces.CategorySecrets,
+		Severity:  interfaces.SeverityMedium,
+		File:      path,
+		StartLine: line.Number,
+		EndLine:   line.Number,
+		Title:     "High-entropy string detected",
+		Description: fmt.Sprintf(
+			"Line %d contains a high-entropy string (%.2f bits/char) that may be a secret.",
+			line.Number, entropy,
+		),
+		Suggestion: "Verify this is not a hardcoded secret. If it is, move it to environment variables.",
+		Source:     "secrets",
+		Confidence: 0.60,
+		Metadata: map[string]any{
+			"entropy": entropy,
+			"length":  len(token),
+		},
+	}, true
+}
+
+// highEntropyTokens returns the tokens on a line whose entropy marks them as
+// possible secrets, in the order they appear.
+func (s *SecretsAnalyzer) highEntropyTokens(path, content string) []string {
+	if isChecksumLine(content) {
+		return nil
+	}
 
 	// Skip lines that are clearly CSS/styling or JSX attribute values.
-	if isFrontendStylingLine(line.Content) {
-		return interfaces.Finding{}, false
+	if isFrontendStylingLine(content) {
+		return nil
 	}
-	if isFrontendFile(path) && isJSXAttributeValue(line.Content) {
-		return interfaces.Finding{}, false
+	if isFrontendFile(path) && isJSXAttributeValue(content) {
+		return nil
 	}
 
 	// Use a higher entropy threshold for frontend files because CSS class
@@ -324,8 +357,8 @@ func (s *SecretsAnalyzer) checkEntropy(path string, line interfaces.Line) (inter
 		threshold = 5.5
 	}
 
-	tokens := extractTokens(line.Content)
-	for _, token := range tokens {
+	var found []string
+	for _, token := range extractTokens(content) {
 		if len(token) < s.entropyMinLength {
 			continue
 		}
@@ -336,34 +369,14 @@ func (s *SecretsAnalyzer) checkEntropy(path string, line interfaces.Line) (inter
 		}
 		// URLs are references, not secrets. Their path segments often have
 		// high entropy (image hashes, resource IDs) but are public.
-		if isTokenPartOfURL(line.Content, token) {
+		if isTokenPartOfURL(content, token) {
 			continue
 		}
-		entropy := shannonEntropy(token)
-		if entropy > threshold {
-			return interfaces.Finding{
-				ID:        fmt.Sprintf("SEC-ENTROPY-%d", line.Number),
-				Category:  interfaces.CategorySecrets,
-				Severity:  interfaces.SeverityMedium,
-				File:      path,
-				StartLine: line.Number,
-				EndLine:   line.Number,
-				Title:     "High-entropy string detected",
-				Description: fmt.Sprintf(
-					"Line %d contains a high-entropy string (%.2f bits/char) that may be a secret.",
-					line.Number, entropy,
-				),
-				Suggestion: "Verify this is not a hardcoded secret. If it is, move it to environment variables.",
-				Source:     "secrets",
-				Confidence: 0.60,
-				Metadata: map[string]any{
-					"entropy": entropy,
-					"length":  len(token),
-				},
-			}, true
+		if shannonEntropy(token) > threshold {
+			found = append(found, token)
 		}
 	}
-	return interfaces.Finding{}, false
+	return found
 }
 
 // shannonEntropy calculates the Shannon entropy of a string in bits per character.
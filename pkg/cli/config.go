@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/toyinlola/shipsafe/pkg/interfaces"
 	"gopkg.in/yaml.v3"
 )
 
@@ -26,6 +30,219 @@ type AIConfig struct {
 	Endpoint  string `yaml:"endpoint"`
 	Model     string `yaml:"model"`
 	APIKeyEnv string `yaml:"api_key_env"`
+
+	// Sovereignty restricts which endpoints review prompts may be sent to.
+	// A blocked endpoint fails the scan rather than skipping AI review.
+	Sovereignty AISovereigntyConfig `yaml:"sovereignty"`
+
+	// Cassette is the recorded interactions file served by the "replay"
+	// provider. RecordCassette, when set, records the live provider's
+	// interactions to that file. Both are relative to the config file.
+	Cassette       string `yaml:"cassette"`
+	RecordCassette string `yaml:"record_cassette"`
+
+	// Models, when set, runs every review pass against each listed model and
+	// keeps only findings that at least MinAgreement of them report (default:
+	// a strict majority). Unset fields of an entry inherit the settings above.
+	Models       []AIModelConfig `yaml:"models"`
+	MinAgreement int             `yaml:"min_agreement"`
+
+	// Fallbacks are tried in order when the provider above is unavailable or
+	// fails a request. Unset fields inherit the settings above, as for Models.
+	// Consensus models do not fall back.
+	Fallbacks []AIModelConfig `yaml:"fallbacks"`
+
+	// Concurrency is the number of review passes run in parallel (default 1).
+	Concurrency int `yaml:"concurrency"`
+	// MaxChunks bounds how many budget-sized diff chunks are reviewed (default 8).
+	MaxChunks int `yaml:"max_chunks"`
+
+	// Token budgeting. The diff context budget is derived from the model's
+	// context window, looked up by model name unless ContextWindow is set.
+	ContextWindow    int    `yaml:"context_window"`     // tokens; 0 = from the model table
+	MaxContextTokens int    `yaml:"max_context_tokens"` // caps the derived budget; 0 = no cap
+	TokenizerFile    string `yaml:"tokenizer_file"`     // tiktoken vocabulary (e.g. cl100k_base.tiktoken); empty = estimate
+
+	// ContextFiles are glob patterns (relative to the repository root) for
+	// files sent to the model as reference material, e.g. "ARCHITECTURE.md".
+	ContextFiles []string `yaml:"context_files"`
+	// FocusAreas are short statements of current review priorities.
+	FocusAreas []string `yaml:"focus_areas"`
+
+	// LineTolerance is how many lines from an added line a finding may point
+	// and still be moved onto it; findings further away, or on files outside
+	// the diff, are discarded. 0 = default (5), negative = added lines only.
+	LineTolerance int `yaml:"line_tolerance"`
+
+	// FixPatches asks the model for a patch for each high and critical
+	// finding; patches that apply cleanly are shown in the report and, in CI
+	// on GitHub, posted as suggested changes on the lines they replace.
+	FixPatches bool `yaml:"fix_patches"`
+
+	// Walkthrough adds a pass that summarises the change, file by file, and
+	// names the riskiest areas; it is shown at the top of the report.
+	Walkthrough bool `yaml:"walkthrough"`
+
+	// Agentic lets review passes call read-only repository tools before
+	// answering. Requires an openai-compatible provider with function calling.
+	Agentic AIAgenticConfig `yaml:"agentic"`
+
+	// Retrieval adds repository code related to each changed hunk to the
+	// review context, from a local embedding index built by "shipsafe index".
+	Retrieval AIRetrievalConfig `yaml:"retrieval"`
+
+	// Triage asks the model whether each medium-or-higher static finding is a
+	// true positive and adjusts its confidence accordingly. Findings are never
+	// removed; the model's reasoning is attached to them. Secrets findings are
+	// not triaged, since the model only ever sees their redacted values.
+	Triage bool `yaml:"triage"`
+
+	// TestSuggestions asks the model for test function skeletons for files
+	// with coverage findings, following the test files in the diff, and
+	// attaches them to the findings.
+	TestSuggestions bool `yaml:"test_suggestions"`
+
+	// Passes are user-defined review passes run alongside the built-in ones.
+	Passes []AIPassConfig `yaml:"passes"`
+	// DisabledPasses lists built-in passes to skip ("semantic", "logic", "convention").
+	DisabledPasses []string `yaml:"disabled_passes"`
+
+	// LanguagePrompts overrides the built-in language-specific review points,
+	// keyed by language ("go", "python", ...) and then by pass name. A language
+	// listed here replaces its built-in pack; an empty entry turns it off.
+	LanguagePrompts map[string]map[string]string `yaml:"language_prompts"`
+
+	// Retry and rate-limit settings for LLM requests.
+	MaxRetries        int           `yaml:"max_retries"`         // 0 = default (3), negative disables retries
+	InitialBackoff    time.Duration `yaml:"initial_backoff"`     // e.g. "1s"
+	MaxBackoff        time.Duration `yaml:"max_backoff"`         // e.g. "30s"
+	RequestsPerMinute int           `yaml:"requests_per_minute"` // 0 = unlimited
+	TokensPerMinute   int           `yaml:"tokens_per_minute"`   // 0 = unlimited
+
+	// Stream receives responses as server-sent events (openai-compatible
+	// providers only). A streamed request has no total timeout; it fails when
+	// no data arrives for StreamIdleTimeout, so slow local models that are
+	// still producing tokens are not cut off.
+	Stream            bool          `yaml:"stream"`
+	StreamIdleTimeout time.Duration `yaml:"stream_idle_timeout"` // 0 = default (60s)
+
+	Cache AICacheConfig `yaml:"cache"`
+
+	// Prices maps model names (or name prefixes) to token prices, used to
+	// estimate the cost of each review. No prices are built in.
+	Prices map[string]AIPrice `yaml:"prices"`
+}
+
+// AISovereigntyConfig is the data-sovereignty policy for AI endpoints.
+type AISovereigntyConfig struct {
+	// AllowedHosts lists permitted endpoint hosts: names ("llm.internal"),
+	// wildcard subdomains ("*.corp.example"), IPs or CIDRs ("10.0.0.0/8").
+	AllowedHosts []string `yaml:"allowed_hosts"`
+	// PrivateOnly refuses endpoints resolving to non-private addresses.
+	PrivateOnly bool `yaml:"private_only"`
+}
+
+// AIAgenticConfig configures tool-using review passes.
+type AIAgenticConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	MaxTurns int      `yaml:"max_turns"` // tool rounds per pass; 0 = default (4)
+	Passes   []string `yaml:"passes"`    // passes that may call tools; empty = all
+}
+
+// AIRetrievalConfig configures the embedding index of related repository code.
+type AIRetrievalConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Model     string `yaml:"model"`       // embedding model, e.g. "nomic-embed-text"
+	Endpoint  string `yaml:"endpoint"`    // OpenAI-compatible; empty = ai.endpoint
+	APIKeyEnv string `yaml:"api_key_env"` // empty = ai.api_key_env
+	Index     string `yaml:"index"`       // relative to the config file; empty = .shipsafe/index.gob in the repository
+	TopK      int    `yaml:"top_k"`       // related chunks per changed hunk; 0 = default (3)
+}
+
+// AIModelConfig is one model in a consensus review or fallback chain.
+type AIModelConfig struct {
+	Model     string `yaml:"model"`
+	Provider  string `yaml:"provider"`
+	Endpoint  string `yaml:"endpoint"`
+	APIKeyEnv string `yaml:"api_key_env"`
+}
+
+// ForModel returns the AI configuration for one consensus or fallback model:
+// c with the model's provider settings applied. An entry that switches
+// provider without an endpoint gets that provider's default endpoint, an
+// entry without a model keeps c's, and cassette paths get a per-model suffix
+// because recordings are not keyed by model.
+func (c AIConfig) ForModel(m AIModelConfig) AIConfig {
+	out := c
+	out.Models = nil
+	out.Fallbacks = nil
+	if m.Model != "" {
+		out.Model = m.Model
+	}
+	if m.Provider != "" && m.Provider != c.Provider {
+		out.Provider = m.Provider
+		out.Endpoint = defaultAIEndpoint(m.Provider)
+	}
+	if m.Endpoint != "" {
+		out.Endpoint = m.Endpoint
+	}
+	if m.APIKeyEnv != "" {
+		out.APIKeyEnv = m.APIKeyEnv
+	}
+	out.Cassette = modelCassettePath(c.Cassette, out.Model)
+	out.RecordCassette = modelCassettePath(c.RecordCassette, out.Model)
+	return out
+}
+
+// modelCassettePath inserts a sanitised model name before the extension of
+// path: "ai.json" becomes "ai.llama3-8b.json". Empty paths stay empty.
+func modelCassettePath(path, model string) string {
+	if path == "" {
+		return ""
+	}
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, model)
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + safe + ext
+}
+
+// AIPrice is a model's token price in USD per million tokens.
+type AIPrice struct {
+	Input  float64 `yaml:"input"`  // prompt tokens
+	Output float64 `yaml:"output"` // completion tokens
+}
+
+// AICacheConfig controls the on-disk cache of LLM responses.
+type AICacheConfig struct {
+	Enabled   *bool         `yaml:"enabled"`
+	Dir       string        `yaml:"dir"`
+	TTL       time.Duration `yaml:"ttl"`         // e.g. "168h"
+	MaxSizeMB int           `yaml:"max_size_mb"` // total cache size before eviction
+}
+
+// IsEnabled reports whether the AI response cache is enabled.
+// Returns true by default if not explicitly set.
+func (c AICacheConfig) IsEnabled() bool {
+	if c.Enabled == nil {
+		return true
+	}
+	return *c.Enabled
+}
+
+// AIPassConfig defines a custom AI review pass.
+type AIPassConfig struct {
+	Name             string   `yaml:"name"`
+	Category         string   `yaml:"category"`           // finding category, defaults to "logic"
+	SystemPrompt     string   `yaml:"system_prompt"`      // inline review instructions
+	SystemPromptFile string   `yaml:"system_prompt_file"` // or a file, relative to the config file
+	Temperature      *float64 `yaml:"temperature"`        // unset = the default, 0.1
+	MaxTokens        int      `yaml:"max_tokens"`
 }
 
 // ThresholdConfig holds the trust score thresholds.
@@ -94,10 +311,108 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("cli: parsing config %s: %w", path, err)
 	}
 
+	if err := resolveAIPasses(cfg, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("cli: config %s: %w", path, err)
+	}
+	for i, m := range cfg.AI.Models {
+		if strings.TrimSpace(m.Model) == "" {
+			return nil, fmt.Errorf("cli: config %s: ai.models[%d]: model is required", path, i)
+		}
+	}
+	for i, m := range cfg.AI.Fallbacks {
+		if m == (AIModelConfig{}) {
+			return nil, fmt.Errorf("cli: config %s: ai.fallbacks[%d]: set at least one of model, provider or endpoint", path, i)
+		}
+	}
+	if cfg.AI.Retrieval.Enabled && strings.TrimSpace(cfg.AI.Retrieval.Model) == "" {
+		return nil, fmt.Errorf("cli: config %s: ai.retrieval.model is required when retrieval is enabled", path)
+	}
+	cfg.AI.Cassette = resolveConfigPath(filepath.Dir(path), cfg.AI.Cassette)
+	cfg.AI.RecordCassette = resolveConfigPath(filepath.Dir(path), cfg.AI.RecordCassette)
+	cfg.AI.Retrieval.Index = resolveConfigPath(filepath.Dir(path), cfg.AI.Retrieval.Index)
+
 	applyDefaults(cfg)
 	return cfg, nil
 }
 
+// builtinAIPasses are the names of the built-in AI review passes.
+var builtinAIPasses = map[string]bool{"semantic": true, "logic": true, "convention": true}
+
+// resolveAIPasses validates custom AI passes and loads prompts from
+// system_prompt_file, resolved relative to the config file's directory. It
+// also checks that disabled passes name built-in passes and that language
+// prompts name known passes.
+func resolveAIPasses(cfg *Config, baseDir string) error {
+	for i, name := range cfg.AI.DisabledPasses {
+		if !builtinAIPasses[name] {
+			return fmt.Errorf("ai.disabled_passes[%d]: unknown built-in pass %q", i, name)
+		}
+	}
+
+	seen := make(map[string]bool, len(builtinAIPasses)+len(cfg.AI.Passes))
+	for name := range builtinAIPasses {
+		seen[name] = true
+	}
+	for i := range cfg.AI.Passes {
+		pass := &cfg.AI.Passes[i]
+		if pass.Name == "" {
+			return fmt.Errorf("ai.passes[%d]: name is required", i)
+		}
+		if seen[pass.Name] {
+			return fmt.Errorf("ai.passes[%d]: duplicate pass name %q", i, pass.Name)
+		}
+		seen[pass.Name] = true
+
+		if pass.SystemPromptFile != "" {
+			if pass.SystemPrompt != "" {
+				return fmt.Errorf("ai.passes[%d] (%s): set system_prompt or system_prompt_file, not both", i, pass.Name)
+			}
+			data, err := os.ReadFile(resolveConfigPath(baseDir, pass.SystemPromptFile))
+			if err != nil {
+				return fmt.Errorf("ai.passes[%d] (%s): reading system_prompt_file: %w", i, pass.Name, err)
+			}
+			pass.SystemPrompt = string(data)
+		}
+		if pass.SystemPrompt == "" {
+			return fmt.Errorf("ai.passes[%d] (%s): system_prompt or system_prompt_file is required", i, pass.Name)
+		}
+		if pass.Category != "" && !validCategories[interfaces.Category(pass.Category)] {
+			return fmt.Errorf("ai.passes[%d] (%s): unknown category %q", i, pass.Name, pass.Category)
+		}
+		if pass.Temperature != nil && *pass.Temperature < 0 {
+			return fmt.Errorf("ai.passes[%d] (%s): temperature must not be negative", i, pass.Name)
+		}
+	}
+	for lang, pack := range cfg.AI.LanguagePrompts {
+		for name := range pack {
+			if !seen[name] {
+				return fmt.Errorf("ai.language_prompts.%s: unknown pass %q", lang, name)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveConfigPath makes a non-empty relative path relative to baseDir.
+func resolveConfigPath(baseDir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(baseDir, p)
+}
+
+// validCategories are the finding categories accepted for custom AI passes.
+var validCategories = map[interfaces.Category]bool{
+	interfaces.CategoryComplexity: true,
+	interfaces.CategoryCoverage:   true,
+	interfaces.CategorySecurity:   true,
+	interfaces.CategorySecrets:    true,
+	interfaces.CategoryPattern:    true,
+	interfaces.CategoryImport:     true,
+	interfaces.CategoryLogic:      true,
+	interfaces.CategoryConvention: true,
+}
+
 // DefaultConfig returns a Config with sensible defaults matching the documented
 // .shipsafe.yml schema.
 func DefaultConfig() *Config {
@@ -137,9 +452,39 @@ func applyDefaults(cfg *Config) {
 	}
 
 	if cfg.AI.Endpoint == "" {
-		cfg.AI.Endpoint = "https://api.openai.com/v1"
+		cfg.AI.Endpoint = defaultAIEndpoint(cfg.AI.Provider)
+	}
+	if cfg.AI.Provider == "anthropic" && cfg.AI.Model == "" {
+		cfg.AI.Model = "claude-3-5-haiku-latest"
 	}
 	if cfg.AI.Model == "" {
 		cfg.AI.Model = "gpt-4o-mini"
 	}
+	if cfg.AI.Cache.Dir == "" {
+		cfg.AI.Cache.Dir = defaultAICacheDir()
+	}
+	if cfg.AI.Cache.TTL == 0 {
+		cfg.AI.Cache.TTL = 7 * 24 * time.Hour
+	}
+	if cfg.AI.Cache.MaxSizeMB == 0 {
+		cfg.AI.Cache.MaxSizeMB = 100
+	}
+}
+
+// defaultAICacheDir returns the per-user cache directory for AI responses,
+// falling back to a directory in the working tree if none is available.
+func defaultAICacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "shipsafe", "ai")
+	}
+	return filepath.Join(".shipsafe", "cache", "ai")
+}
+
+// defaultAIEndpoint returns the API endpoint used when a provider is
+// configured without one.
+func defaultAIEndpoint(provider string) string {
+	if provider == "anthropic" {
+		return "https://api.anthropic.com/v1"
+	}
+	return "https://api.openai.com/v1"
 }
@@ -130,14 +130,67 @@ type DiffMetadata struct {
 
 // Report is the final output of a ShipSafe analysis run.
 type Report struct {
-	ID         string         `json:"id"`
-	Timestamp  time.Time      `json:"timestamp"`
-	TrustScore TrustScore     `json:"trust_score"`
-	Findings   []Finding      `json:"findings"`
-	Summary    string         `json:"summary"`
-	DiffMeta   DiffMetadata   `json:"diff_metadata"`
-	Duration   time.Duration  `json:"duration"`
-	Config     map[string]any `json:"config,omitempty"`
+	ID         string           `json:"id"`
+	Timestamp  time.Time        `json:"timestamp"`
+	TrustScore TrustScore       `json:"trust_score"`
+	Findings   []Finding        `json:"findings"`
+	Summary    string           `json:"summary"`
+	DiffMeta   DiffMetadata     `json:"diff_metadata"`
+	Duration   time.Duration    `json:"duration"`
+	Config     map[string]any   `json:"config,omitempty"`
+	AIReview   *AIReviewSummary `json:"ai_review,omitempty"`
+	// Walkthrough is the AI-written overview of the change. Nil when AI
+	// review is off or the walkthrough was not requested or failed.
+	Walkthrough *Walkthrough `json:"walkthrough,omitempty"`
+}
+
+// Walkthrough is an AI-written overview of a change for reviewers.
+type Walkthrough struct {
+	Summary string            `json:"summary"`
+	Files   []FileWalkthrough `json:"files,omitempty"`
+	Risks   []RiskArea        `json:"risks,omitempty"`
+}
+
+// FileWalkthrough summarises the change to one file.
+type FileWalkthrough struct {
+	File    string `json:"file"`
+	Summary string `json:"summary"`
+}
+
+// RiskArea is a part of the change that deserves the closest review.
+type RiskArea struct {
+	File   string `json:"file,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// AIReviewSummary describes how the AI review ran. It is nil when AI review
+// was disabled or did not produce a result.
+type AIReviewSummary struct {
+	Chunks        int      `json:"chunks"`
+	ReviewedFiles []string `json:"reviewed_files,omitempty"`
+	SkippedFiles  []string `json:"skipped_files,omitempty"`
+
+	// Token usage summed over all LLM calls, and per review pass.
+	Usage     TokenUsage            `json:"usage"`
+	PassUsage map[string]TokenUsage `json:"pass_usage,omitempty"`
+	// CostUSD is the estimated cost of the review. Nil when no price is
+	// configured for the model.
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+
+	// Endpoints are the LLM endpoints review prompts could be sent to, and
+	// EndpointPolicy the sovereignty policy they were checked against (empty
+	// when none is configured). Recorded for compliance audits.
+	Endpoints      []string `json:"endpoints,omitempty"`
+	EndpointPolicy string   `json:"endpoint_policy,omitempty"`
+	// AnsweredBy names the providers ("model @ endpoint") that answered, in
+	// fallback order. More than one means the review fell back mid-run.
+	AnsweredBy []string `json:"answered_by,omitempty"`
+}
+
+// TokenUsage counts the tokens consumed by LLM calls.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 // AIReviewOptions configures the AI review pass.
@@ -15,6 +15,23 @@ type VCSProvider interface {
 	SetStatus(ctx context.Context, sha string, status StatusState, description string) error
 }
 
+// ReviewComment is a pull request comment anchored to lines of a file on the
+// post-change side of the diff.
+type ReviewComment struct {
+	Path      string
+	StartLine int // first line; equal to Line for a single-line comment
+	Line      int // last line
+	Body      string
+}
+
+// ReviewCommenter is implemented by VCS providers that can post comments
+// anchored to lines of a pull request, such as GitHub suggested changes.
+type ReviewCommenter interface {
+	// PostReviewComments posts comments as a single review on the latest
+	// commit of the PR/MR.
+	PostReviewComments(ctx context.Context, prRef string, comments []ReviewComment) error
+}
+
 // DiffParser parses raw diff content into structured Diff objects.
 // Used for both file-based diffs and piped input.
 type DiffParser interface {
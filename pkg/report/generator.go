@@ -38,16 +38,65 @@ func (g *Generator) Generate(results []*interfaces.AnalysisResult, score *interf
 	summary := buildSummary(score, findings)
 
 	return &interfaces.Report{
-		ID:         generateID(),
-		Timestamp:  time.Now(),
-		TrustScore: *score,
-		Findings:   findings,
-		Summary:    summary,
-		DiffMeta:   meta,
-		Duration:   time.Since(start),
+		ID:          generateID(),
+		Timestamp:   time.Now(),
+		TrustScore:  *score,
+		Findings:    findings,
+		Summary:     summary,
+		DiffMeta:    meta,
+		Duration:    time.Since(start),
+		AIReview:    buildAIReviewSummary(results),
+		Walkthrough: aiWalkthrough(results),
 	}
 }
 
+// aiWalkthrough returns the walkthrough written by the AI reviewer, or nil if
+// AI review did not run or no walkthrough was produced.
+func aiWalkthrough(results []*interfaces.AnalysisResult) *interfaces.Walkthrough {
+	for _, r := range results {
+		if r == nil || r.AnalyzerName != "ai-reviewer" || r.Error != nil {
+			continue
+		}
+		if w, ok := r.Metadata["walkthrough"].(*interfaces.Walkthrough); ok {
+			return w
+		}
+	}
+	return nil
+}
+
+// buildAIReviewSummary extracts AI review coverage from the ai-reviewer result's
+// metadata. Returns nil if AI review did not run.
+func buildAIReviewSummary(results []*interfaces.AnalysisResult) *interfaces.AIReviewSummary {
+	for _, r := range results {
+		if r == nil || r.AnalyzerName != "ai-reviewer" || r.Error != nil {
+			continue
+		}
+		summary := &interfaces.AIReviewSummary{}
+		if n, ok := r.Metadata["chunks"].(int); ok {
+			summary.Chunks = n
+		}
+		if files, ok := r.Metadata["reviewed_files"].([]string); ok {
+			summary.ReviewedFiles = files
+		}
+		if files, ok := r.Metadata["skipped_files"].([]string); ok {
+			summary.SkippedFiles = files
+		}
+		summary.Usage.PromptTokens, _ = r.Metadata["prompt_tokens"].(int)
+		summary.Usage.CompletionTokens, _ = r.Metadata["completion_tokens"].(int)
+		if usage, ok := r.Metadata["pass_usage"].(map[string]interfaces.TokenUsage); ok {
+			summary.PassUsage = usage
+		}
+		if cost, ok := r.Metadata["cost_usd"].(float64); ok {
+			summary.CostUSD = &cost
+		}
+		summary.Endpoints, _ = r.Metadata["endpoints"].([]string)
+		summary.EndpointPolicy, _ = r.Metadata["endpoint_policy"].(string)
+		summary.AnsweredBy, _ = r.Metadata["answered_by"].([]string)
+		return summary
+	}
+	return nil
+}
+
 // collectFindings merges findings from all analysis results.
 func collectFindings(results []*interfaces.AnalysisResult) []interfaces.Finding {
 	var all []interfaces.Finding
@@ -19,8 +19,10 @@ func NewMarkdownFormatter() *MarkdownFormatter {
 // Format writes the report as Markdown to the given writer.
 func (f *MarkdownFormatter) Format(w io.Writer, report *interfaces.Report) error {
 	f.writeHeader(w, report)
+	f.writeWalkthrough(w, report)
 	f.writeSummaryTable(w, report)
 	f.writeFindings(w, report)
+	f.writeAIReview(w, report)
 	f.writeFooter(w, report)
 	return nil
 }
@@ -30,6 +32,44 @@ func (f *MarkdownFormatter) writeHeader(w io.Writer, report *interfaces.Report)
 	fmt.Fprintf(w, "# ShipSafe Verification Report %s\n\n", badge)
 }
 
+func (f *MarkdownFormatter) writeWalkthrough(w io.Writer, report *interfaces.Report) {
+	wt := report.Walkthrough
+	if wt == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "## Walkthrough\n\n%s\n\n", wt.Summary)
+
+	if len(wt.Risks) > 0 {
+		fmt.Fprintln(w, "**Review closely:**")
+		fmt.Fprintln(w)
+		for _, risk := range wt.Risks {
+			if risk.File != "" {
+				fmt.Fprintf(w, "- `%s` — %s\n", risk.File, risk.Reason)
+			} else {
+				fmt.Fprintf(w, "- %s\n", risk.Reason)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(wt.Files) > 0 {
+		fmt.Fprintf(w, "<details>\n<summary>Changes by file (%d)</summary>\n\n", len(wt.Files))
+		fmt.Fprintln(w, "| File | Change |")
+		fmt.Fprintln(w, "|------|--------|")
+		for _, file := range wt.Files {
+			fmt.Fprintf(w, "| `%s` | %s |\n", file.File, escapeTableCell(file.Summary))
+		}
+		fmt.Fprintf(w, "\n</details>\n\n")
+	}
+}
+
+// escapeTableCell keeps free text from breaking a Markdown table row.
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
 func (f *MarkdownFormatter) writeSummaryTable(w io.Writer, report *interfaces.Report) {
 	score := report.TrustScore
 	meta := report.DiffMeta
@@ -93,6 +133,11 @@ func (f *MarkdownFormatter) writeFindings(w io.Writer, report *interfaces.Report
 			if finding.Suggestion != "" {
 				fmt.Fprintf(w, "**Suggestion:** %s\n\n", finding.Suggestion)
 			}
+			if note, ok := triageNote(finding); ok {
+				fmt.Fprintf(w, "**AI triage:** %s\n\n", note)
+			}
+			writeSuggestedChange(w, finding)
+			writeSuggestedTests(w, finding)
 			fmt.Fprintf(w, "*Source: %s | Confidence: %.0f%%*\n\n", finding.Source, finding.Confidence*100)
 			fmt.Fprintln(w, "</details>")
 			fmt.Fprintln(w)
@@ -100,10 +145,158 @@ func (f *MarkdownFormatter) writeFindings(w io.Writer, report *interfaces.Report
 	}
 }
 
+// triageNote describes the AI triage verdict recorded on a static finding.
+func triageNote(finding interfaces.Finding) (string, bool) {
+	verdict, ok := finding.Metadata["triage_verdict"].(string)
+	if !ok {
+		return "", false
+	}
+	reasoning, _ := finding.Metadata["triage_reasoning"].(string)
+	label := map[string]string{
+		"true_positive":  "confirmed",
+		"false_positive": "likely false positive",
+		"uncertain":      "uncertain",
+	}[verdict]
+	if label == "" {
+		label = verdict
+	}
+	return fmt.Sprintf("%s — %s", label, reasoning), true
+}
+
+// writeSuggestedChange renders an AI fix patch as a diff block. PR-level
+// comments cannot carry applicable suggestions; see SuggestedChanges for the
+// line-anchored form.
+func writeSuggestedChange(w io.Writer, finding interfaces.Finding) {
+	patch, ok := finding.Metadata["fix_patch"].(string)
+	if !ok {
+		return
+	}
+	start, _ := finding.Metadata["fix_start_line"].(int)
+	end, _ := finding.Metadata["fix_end_line"].(int)
+
+	if start == end {
+		fmt.Fprintf(w, "**Suggested fix** (line %d):\n\n", start)
+	} else {
+		fmt.Fprintf(w, "**Suggested fix** (lines %d–%d):\n\n", start, end)
+	}
+	fence := codeFence(patch)
+	fmt.Fprintf(w, "%sdiff\n%s\n%s\n\n", fence, strings.TrimRight(patch, "\n"), fence)
+}
+
+// SuggestedChanges returns the AI fix patches in r as review comments holding
+// suggested-change blocks, which authors can commit in one click when posted
+// anchored to the lines they replace.
+func SuggestedChanges(r *interfaces.Report) []interfaces.ReviewComment {
+	var comments []interfaces.ReviewComment
+	for _, finding := range r.Findings {
+		replacement, ok := finding.Metadata["fix_replacement"].(string)
+		if !ok {
+			continue
+		}
+		start, _ := finding.Metadata["fix_start_line"].(int)
+		end, _ := finding.Metadata["fix_end_line"].(int)
+		if start <= 0 || end < start {
+			continue
+		}
+		if replacement != "" {
+			replacement += "\n" // An empty block deletes the lines.
+		}
+		fence := codeFence(replacement)
+		comments = append(comments, interfaces.ReviewComment{
+			Path:      finding.File,
+			StartLine: start,
+			Line:      end,
+			Body: fmt.Sprintf("**%s** [%s]\n\n%ssuggestion\n%s%s\n",
+				finding.Title, strings.ToUpper(string(finding.Severity)), fence, replacement, fence),
+		})
+	}
+	return comments
+}
+
+// codeFence returns a backtick fence longer than any backtick run in s.
+func codeFence(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+// writeSuggestedTests renders the AI test skeleton attached to a coverage
+// finding as a code block.
+func writeSuggestedTests(w io.Writer, finding interfaces.Finding) {
+	code, ok := finding.Metadata["suggested_tests"].(string)
+	if !ok {
+		return
+	}
+	file, _ := finding.Metadata["suggested_test_file"].(string)
+	if framework, ok := finding.Metadata["test_framework"].(string); ok {
+		fmt.Fprintf(w, "**Suggested tests** for `%s` (%s):\n\n", file, framework)
+	} else {
+		fmt.Fprintf(w, "**Suggested tests** for `%s`:\n\n", file)
+	}
+
+	fence := codeFence(code)
+	language, _ := finding.Metadata["test_language"].(string)
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n\n", fence, language, code, fence)
+}
+
+func (f *MarkdownFormatter) writeAIReview(w io.Writer, report *interfaces.Report) {
+	ai := report.AIReview
+	if ai == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "**AI usage:** %s\n\n", formatAIUsage(ai))
+	if len(ai.Endpoints) > 0 {
+		fmt.Fprintf(w, "**AI endpoints:** %s\n\n", formatAIEndpoints(ai, "`"))
+	}
+
+	fmt.Fprintf(w, "**AI review coverage:** %d file(s) reviewed in %d chunk(s)", len(ai.ReviewedFiles), ai.Chunks)
+	if len(ai.SkippedFiles) == 0 {
+		fmt.Fprint(w, "\n\n")
+		return
+	}
+	fmt.Fprintf(w, ", %d skipped\n\n", len(ai.SkippedFiles))
+	fmt.Fprintf(w, "<details>\n<summary>Files not reviewed by AI</summary>\n\n")
+	for _, path := range ai.SkippedFiles {
+		fmt.Fprintf(w, "- `%s`\n", path)
+	}
+	fmt.Fprintf(w, "\n</details>\n\n")
+}
+
 func (f *MarkdownFormatter) writeFooter(w io.Writer, report *interfaces.Report) {
 	fmt.Fprintln(w, "---")
-	fmt.Fprintf(w, "*Report ID: %s | Generated: %s*\n",
+	fmt.Fprintf(w, "*Report ID: %s | Generated: %s",
 		report.ID, report.Timestamp.Format("2006-01-02 15:04:05"))
+	if ai := report.AIReview; ai != nil && len(ai.AnsweredBy) > 0 {
+		fmt.Fprintf(w, " | AI answered by: %s", strings.Join(ai.AnsweredBy, ", "))
+	}
+	fmt.Fprintln(w, "*")
+}
+
+// formatAIEndpoints lists the AI endpoints, each wrapped in quote, followed
+// by the sovereignty policy if one was enforced.
+func formatAIEndpoints(ai *interfaces.AIReviewSummary, quote string) string {
+	quoted := make([]string, len(ai.Endpoints))
+	for i, e := range ai.Endpoints {
+		quoted[i] = quote + e + quote
+	}
+	s := strings.Join(quoted, ", ")
+	if ai.EndpointPolicy != "" {
+		s += fmt.Sprintf(" (policy: %s)", ai.EndpointPolicy)
+	}
+	return s
+}
+
+// formatAIUsage describes the tokens an AI review consumed and, when a price
+// is configured, its estimated cost.
+func formatAIUsage(ai *interfaces.AIReviewSummary) string {
+	s := fmt.Sprintf("%d prompt + %d completion tokens", ai.Usage.PromptTokens, ai.Usage.CompletionTokens)
+	if ai.CostUSD != nil {
+		s += fmt.Sprintf(", est. cost $%.4f", *ai.CostUSD)
+	}
+	return s
 }
 
 // scoreBadge returns a text badge based on the rating.
@@ -30,6 +30,7 @@ func NewTerminalFormatter() *TerminalFormatter {
 // Format writes the report to the given writer using ANSI colors.
 func (f *TerminalFormatter) Format(w io.Writer, report *interfaces.Report) error {
 	f.writeHeader(w, report)
+	f.writeWalkthrough(w, report)
 	f.writeSummary(w, report)
 	f.writeFindings(w, report)
 	f.writeFooter(w, report)
@@ -42,6 +43,32 @@ func (f *TerminalFormatter) writeHeader(w io.Writer, report *interfaces.Report)
 	fmt.Fprintf(w, "%s%s══════════════════════════════════════════%s\n\n", colorBold, colorCyan, colorReset)
 }
 
+func (f *TerminalFormatter) writeWalkthrough(w io.Writer, report *interfaces.Report) {
+	wt := report.Walkthrough
+	if wt == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "  %sWalkthrough%s\n", colorBold, colorReset)
+	fmt.Fprintf(w, "  %s\n\n", wt.Summary)
+	for _, file := range wt.Files {
+		fmt.Fprintf(w, "    %s%s%s: %s\n", colorDim, file.File, colorReset, file.Summary)
+	}
+	if len(wt.Files) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, risk := range wt.Risks {
+		if risk.File != "" {
+			fmt.Fprintf(w, "  %sRisk:%s %s — %s\n", colorYellow, colorReset, risk.File, risk.Reason)
+		} else {
+			fmt.Fprintf(w, "  %sRisk:%s %s\n", colorYellow, colorReset, risk.Reason)
+		}
+	}
+	if len(wt.Risks) > 0 {
+		fmt.Fprintln(w)
+	}
+}
+
 func (f *TerminalFormatter) writeSummary(w io.Writer, report *interfaces.Report) {
 	score := report.TrustScore
 	color := ratingColor(score.Rating)
@@ -96,6 +123,16 @@ func (f *TerminalFormatter) writeFindings(w io.Writer, report *interfaces.Report
 			if finding.Suggestion != "" {
 				fmt.Fprintf(w, "      %s→ %s%s\n", colorCyan, finding.Suggestion, colorReset)
 			}
+			if note, ok := triageNote(finding); ok {
+				fmt.Fprintf(w, "      %sAI triage: %s%s\n", colorDim, note, colorReset)
+			}
+			if code, ok := finding.Metadata["suggested_tests"].(string); ok {
+				file, _ := finding.Metadata["suggested_test_file"].(string)
+				fmt.Fprintf(w, "      %sSuggested tests for %s:%s\n", colorDim, file, colorReset)
+				for _, line := range strings.Split(code, "\n") {
+					fmt.Fprintf(w, "        %s%s%s\n", colorDim, line, colorReset)
+				}
+			}
 			fmt.Fprintln(w)
 		}
 	}
@@ -106,6 +143,20 @@ func (f *TerminalFormatter) writeFooter(w io.Writer, report *interfaces.Report)
 	fmt.Fprintf(w, "  %s%s──────────────────────────────────────────%s\n", colorDim, colorCyan, colorReset)
 	fmt.Fprintf(w, "  %sFiles: %d | +%d/-%d | Report: %s%s\n",
 		colorDim, meta.FilesChanged, meta.Additions, meta.Deletions, report.ID, colorReset)
+	if ai := report.AIReview; ai != nil {
+		fmt.Fprintf(w, "  %sAI review: %d file(s) reviewed in %d chunk(s)", colorDim, len(ai.ReviewedFiles), ai.Chunks)
+		if len(ai.SkippedFiles) > 0 {
+			fmt.Fprintf(w, ", %d skipped: %s", len(ai.SkippedFiles), strings.Join(ai.SkippedFiles, ", "))
+		}
+		fmt.Fprintf(w, "%s\n", colorReset)
+		fmt.Fprintf(w, "  %sAI usage: %s%s\n", colorDim, formatAIUsage(ai), colorReset)
+		if len(ai.Endpoints) > 0 {
+			fmt.Fprintf(w, "  %sAI endpoints: %s%s\n", colorDim, formatAIEndpoints(ai, ""), colorReset)
+		}
+		if len(ai.AnsweredBy) > 0 {
+			fmt.Fprintf(w, "  %sAI answered by: %s%s\n", colorDim, strings.Join(ai.AnsweredBy, ", "), colorReset)
+		}
+	}
 	fmt.Fprintf(w, "  %sGenerated: %s%s\n\n",
 		colorDim, report.Timestamp.Format("2006-01-02 15:04:05"), colorReset)
 }
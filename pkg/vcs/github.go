@@ -131,6 +131,61 @@ func (g *GitHubProvider) PostComment(ctx context.Context, prRef string, body str
 	return nil
 }
 
+// githubReviewComment is a review comment in the pull request reviews API.
+type githubReviewComment struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Body      string `json:"body"`
+}
+
+// PostReviewComments posts comments anchored to lines of a pull request as one
+// review. Unlike PR-level comments, these render suggested-change blocks with
+// a "Commit suggestion" button.
+func (g *GitHubProvider) PostReviewComments(ctx context.Context, prRef string, comments []interfaces.ReviewComment) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%s/reviews", g.baseURL, g.owner, g.repo, prRef)
+
+	review := struct {
+		Event    string                `json:"event"`
+		Comments []githubReviewComment `json:"comments"`
+	}{Event: "COMMENT"}
+	for _, c := range comments {
+		rc := githubReviewComment{Path: c.Path, Line: c.Line, Side: "RIGHT", Body: c.Body}
+		if c.StartLine > 0 && c.StartLine < c.Line {
+			rc.StartLine = c.StartLine
+			rc.StartSide = "RIGHT"
+		}
+		review.Comments = append(review.Comments, rc)
+	}
+	data, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("vcs: marshaling GitHub review: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("vcs: creating GitHub review request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	g.setAuth(req)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vcs: posting GitHub review: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("vcs: GitHub review returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
 // SetStatus sets a commit status on a given SHA.
 func (g *GitHubProvider) SetStatus(ctx context.Context, sha string, status interfaces.StatusState, description string) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", g.baseURL, g.owner, g.repo, sha)
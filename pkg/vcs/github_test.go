@@ -189,3 +189,42 @@ func TestGitHubProvider_NoAuth(t *testing.T) {
 		t.Errorf("expected no auth header, got %q", gotAuth)
 	}
 }
+
+func TestGitHubProvider_PostReviewComments(t *testing.T) {
+	var gotPath string
+	var got struct {
+		Event    string                `json:"event"`
+		Comments []githubReviewComment `json:"comments"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to parse request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := NewGitHubProvider("myorg", "myrepo", "test-token", server.URL)
+	err := provider.PostReviewComments(context.Background(), "42", []interfaces.ReviewComment{
+		{Path: "db.go", StartLine: 10, Line: 10, Body: "single"},
+		{Path: "db.go", StartLine: 12, Line: 14, Body: "range"},
+	})
+	if err != nil {
+		t.Fatalf("PostReviewComments returned error: %v", err)
+	}
+
+	if gotPath != "/repos/myorg/myrepo/pulls/42/reviews" {
+		t.Errorf("unexpected path: %s", gotPath)
+	}
+	if got.Event != "COMMENT" || len(got.Comments) != 2 {
+		t.Fatalf("unexpected review: %+v", got)
+	}
+	if c := got.Comments[0]; c.Line != 10 || c.StartLine != 0 || c.Side != "RIGHT" {
+		t.Errorf("expected a single-line comment without start_line, got %+v", c)
+	}
+	if c := got.Comments[1]; c.StartLine != 12 || c.Line != 14 || c.StartSide != "RIGHT" {
+		t.Errorf("expected a multi-line comment anchored at 12-14, got %+v", c)
+	}
+}
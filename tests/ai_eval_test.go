@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+	"github.com/toyinlola/shipsafe/pkg/ai/eval"
+	"github.com/toyinlola/shipsafe/pkg/vcs"
+)
+
+func TestAIEval_LabeledFixtures(t *testing.T) {
+	ctx := context.Background()
+	cases, err := eval.LoadCases(ctx, fixturesDir(), vcs.NewDiffParser())
+	if err != nil {
+		t.Fatalf("LoadCases: %v", err)
+	}
+
+	scorer := eval.NewScorer(eval.DefaultLineTolerance)
+	reviewer := ai.NewReviewer(&scriptedModel{})
+	for _, c := range cases {
+		result, err := reviewer.Review(ctx, c.Diff, nil)
+		if err != nil {
+			t.Fatalf("Review(%s): %v", c.Name, err)
+		}
+		scorer.Add(c, result.Findings)
+	}
+	r := scorer.Report()
+
+	// The scripted model reports an unchecked Decode error on line 20 of
+	// mixed-issues, which is not among the labeled findings; every diff gets
+	// the same finding, so clean.diff, with no pkg/handler/api.go, drops it.
+	if len(r.Cases) != 2 || r.Cases[0].Name != "clean" || r.Cases[1].Name != "mixed-issues" {
+		t.Fatalf("expected the labeled fixtures, got %+v", r.Cases)
+	}
+	if r.Overall.TruePositives != 0 || r.Overall.FalseNegatives != 2 {
+		t.Errorf("unexpected overall metrics: %+v", r.Overall)
+	}
+	if m := r.Passes["logic"]; m.FalseNegatives != 1 || m.Recall != 0 {
+		t.Errorf("expected the labeled logic finding to be missed, got %+v", m)
+	}
+}
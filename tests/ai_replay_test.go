@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toyinlola/shipsafe/pkg/ai"
+	"github.com/toyinlola/shipsafe/pkg/ai/providers"
+)
+
+// scriptedModel answers the logic pass with a fixed finding and every other
+// pass with no findings, reporting a fixed token usage per call.
+type scriptedModel struct{ calls int }
+
+func (m *scriptedModel) Complete(_ context.Context, _ string, opts ai.CompletionOpts) (ai.Completion, error) {
+	m.calls++
+	usage := ai.Usage{PromptTokens: 1000, CompletionTokens: 50}
+	if strings.Contains(opts.SystemPrompt, "logic error detection") {
+		return ai.Completion{Text: `{"findings": [{"file": "pkg/handler/api.go", "line": 20, "severity": "high",
+			"title": "Unchecked error", "description": "The error from Decode is ignored."}]}`, Usage: usage}, nil
+	}
+	return ai.Completion{Text: `{"findings": []}`, Usage: usage}, nil
+}
+
+func (m *scriptedModel) Available(_ context.Context) bool { return true }
+
+func TestAIReview_ReplayMatchesRecording(t *testing.T) {
+	diff := LoadFixtureDiff(t, "mixed-issues")
+	cassette := filepath.Join(t.TempDir(), "mixed-issues.json")
+	ctx := context.Background()
+
+	model := &scriptedModel{}
+	recorder, err := providers.NewRecordingProvider(model, cassette)
+	if err != nil {
+		t.Fatalf("NewRecordingProvider: %v", err)
+	}
+	recorded, err := ai.NewReviewer(recorder, ai.WithConcurrency(3)).Review(ctx, diff, nil)
+	if err != nil {
+		t.Fatalf("recording review: %v", err)
+	}
+	if len(recorded.Findings) == 0 {
+		t.Fatal("expected the recorded review to produce findings")
+	}
+
+	replay, err := providers.NewReplayProvider(cassette)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	replayed, err := ai.NewReviewer(replay).Review(ctx, diff, nil)
+	if err != nil {
+		t.Fatalf("replayed review: %v", err)
+	}
+
+	if !reflect.DeepEqual(recorded.Findings, replayed.Findings) {
+		t.Errorf("replayed findings differ from recording:\nrecorded: %+v\nreplayed: %+v", recorded.Findings, replayed.Findings)
+	}
+	if want := 1000 * model.calls; replayed.Metadata["prompt_tokens"] != want {
+		t.Errorf("expected replayed prompt_tokens %d, got %v", want, replayed.Metadata["prompt_tokens"])
+	}
+}